@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allenan/herd/internal/control"
+	"github.com/allenan/herd/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var ctlCmd = &cobra.Command{
+	Use:   "ctl <action>",
+	Short: "Send an action to the running sidebar (" + strings.Join(control.Actions, ", ") + ")",
+	Long: `Send an action to the running sidebar. Used by herd's tmux key bindings so
+sessions can be navigated while focus stays in the viewport.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: control.Actions,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		action := args[0]
+		if !control.Valid(action) {
+			return fmt.Errorf("unknown action %q (want one of: %s)", action, strings.Join(control.Actions, ", "))
+		}
+
+		prof, err := profile.Resolve(profileName)
+		if err != nil {
+			return fmt.Errorf("failed to resolve profile: %w", err)
+		}
+		return control.Send(prof.ControlSocketPath(), control.Request{Action: action})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ctlCmd)
+}
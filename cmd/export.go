@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/allenan/herd/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <session-id>",
+	Short: "Export a session's conversation as Markdown or HTML",
+	Long: `Render a session's conversation for sharing. Claude sessions are rebuilt
+from Claude Code's transcripts, with tool calls and edits as diffs in
+collapsible sections. Terminals, and Claude sessions without a transcript,
+export their full tmux scrollback instead. The ID may be shortened to any
+unique prefix, as with herd switch.
+
+The format defaults to HTML when the output file ends in .html, and to
+Markdown otherwise.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringP("output", "o", "", "write to this file instead of stdout")
+	exportCmd.Flags().String("format", "", "markdown or html")
+	exportCmd.Flags().Bool("scrollback", false, "export the tmux scrollback even if there is a transcript")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	scrollback, _ := cmd.Flags().GetBool("scrollback")
+
+	if format == "" {
+		format = "markdown"
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".html" || ext == ".htm" {
+			format = "html"
+		}
+	}
+	if format != "markdown" && format != "md" && format != "html" {
+		return fmt.Errorf("unknown format %q: must be markdown or html", format)
+	}
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	htmux.Init(prof)
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	s, err := state.FindByIDPrefix(args[0])
+	if err != nil {
+		return err
+	}
+
+	doc, err := htmux.ExportSession(s, usage.NewTracker(prof.ClaudeDir()), scrollback)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == "html" {
+		return doc.HTML(w)
+	}
+	return doc.Markdown(w)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "Search the output of all sessions",
+	Long: `Search the output of the profile's live sessions for a regular expression
+(Go syntax). Both the current tmux scrollback and the output logs of
+sessions with logging on are searched, so output that scrolled away is
+found too. Matches are printed per session, oldest first.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGrep,
+}
+
+func init() {
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "match case-insensitively")
+	grepCmd.Flags().Bool("json", false, "print matches as JSON")
+	rootCmd.AddCommand(grepCmd)
+}
+
+func runGrep(cmd *cobra.Command, args []string) error {
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	pattern := args[0]
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	htmux.Init(prof)
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	matches := htmux.Grep(state.Sessions, prof.OutputLogDir(), re)
+	if asJSON {
+		if matches == nil {
+			matches = []htmux.Match{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matches)
+	}
+	for _, m := range matches {
+		fmt.Printf("%s/%s (%s): %s\n", m.Project, m.Session, m.Source, m.Text)
+	}
+	return nil
+}
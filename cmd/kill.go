@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 
 	"github.com/allenan/herd/internal/profile"
 	htmux "github.com/allenan/herd/internal/tmux"
@@ -18,19 +19,8 @@ var killCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to resolve profile: %w", err)
 		}
-		htmux.Init(prof)
-
-		// 1. Kill the tmux server (ignore errors — it may not be running)
-		htmux.TmuxRun("kill-server")
-
-		// 2. Kill any lingering sidebar processes
-		var pattern string
-		if prof.Name != "" {
-			pattern = fmt.Sprintf("herd --sidebar --profile %s", prof.Name)
-		} else {
-			pattern = "herd --sidebar"
-		}
-		exec.Command("pkill", "-f", pattern).Run() //nolint: ignore exit status
+		// 1-2. Kill the tmux server and the sidebar
+		stopProfile(prof)
 
 		// 3. Remove the state directory
 		if err := os.RemoveAll(prof.BaseDir); err != nil {
@@ -46,6 +36,21 @@ var killCmd = &cobra.Command{
 	},
 }
 
+// stopProfile kills a profile's tmux server and any lingering sidebar
+// processes. Errors are ignored: neither may be running.
+func stopProfile(prof *profile.Profile) {
+	htmux.Init(prof)
+	htmux.TmuxRun("kill-server")
+
+	// Anchored at the end, so profile foo spares foo-bar's sidebar and the
+	// default profile spares every named one
+	pattern := "herd --sidebar$"
+	if prof.Name != "" {
+		pattern = fmt.Sprintf("herd --sidebar --profile %s$", regexp.QuoteMeta(prof.Name))
+	}
+	exec.Command("pkill", "-f", pattern).Run() //nolint: ignore exit status
+}
+
 func init() {
 	rootCmd.AddCommand(killCmd)
 }
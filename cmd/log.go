@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Print the status timeline of all sessions for a day",
+	Long: `Print every recorded status change across the profile's sessions for one
+day, oldest first, with how long each status lasted. Sessions keep their
+last 100 changes; the timelines of the last 200 killed sessions are kept
+too.`,
+	Args: cobra.NoArgs,
+	RunE: runLog,
+}
+
+func init() {
+	logCmd.Flags().String("date", "", "day to show, YYYY-MM-DD (default today)")
+	rootCmd.AddCommand(logCmd)
+}
+
+// logEntry is one status change of one session.
+type logEntry struct {
+	project string
+	name    string
+	at      time.Time
+	until   time.Time // next change or the session's end; zero while ongoing
+	st      session.Status
+	current bool // st is still the session's status
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	dateFlag, _ := cmd.Flags().GetString("date")
+
+	day := time.Now()
+	if dateFlag != "" {
+		d, err := time.ParseInLocation("2006-01-02", dateFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q: want YYYY-MM-DD", dateFlag)
+		}
+		day = d
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	var entries []logEntry
+	add := func(project, name string, timeline []session.StatusChange, endedAt time.Time, status session.Status) {
+		for j, ch := range timeline {
+			if ch.At.Before(start) || !ch.At.Before(end) {
+				continue
+			}
+			e := logEntry{project: project, name: name, at: ch.At, st: ch.Status}
+			if j+1 < len(timeline) {
+				e.until = timeline[j+1].At
+			} else {
+				e.until = endedAt
+				e.current = endedAt.IsZero() && status == ch.Status
+			}
+			entries = append(entries, e)
+		}
+	}
+	for _, e := range state.Ended {
+		add(e.Project, e.Name+" (ended)", e.Timeline, e.EndedAt, "")
+	}
+	for i := range state.Sessions {
+		s := &state.Sessions[i]
+		add(s.Project, s.DisplayName(), s.Timeline, time.Time{}, s.Status)
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No status changes recorded on %s.\n", start.Format("2006-01-02"))
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].at.Before(entries[j].at) })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tPROJECT\tSESSION\tSTATUS\tFOR")
+	for _, e := range entries {
+		dur := "ongoing"
+		if !e.until.IsZero() {
+			dur = e.until.Sub(e.at).Round(time.Second).String()
+		} else if e.current {
+			dur = time.Since(e.at).Round(time.Second).String() + " (ongoing)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			e.at.Local().Format("15:04:05"), e.project, e.name, e.st, dur)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/allenan/herd/internal/outlog"
+	"github.com/spf13/cobra"
+)
+
+var logWriteCmd = &cobra.Command{
+	Use:    "log-write <path>",
+	Short:  "Write a pane's output from stdin to a rotating log (internal)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w, err := outlog.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		_, err = io.Copy(w, os.Stdin)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logWriteCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List sessions",
+	Long: `List the sessions of a profile as last recorded by the sidebar.
+With --tag, only sessions carrying every given tag are listed. Cost is
+estimated from Claude Code's transcripts at list prices.`,
+	Args: cobra.NoArgs,
+	RunE: runLs,
+}
+
+func init() {
+	lsCmd.Flags().StringSlice("tag", nil, "only list sessions with this tag (repeatable)")
+	lsCmd.Flags().Bool("json", false, "print sessions and usage as JSON")
+	rootCmd.AddCommand(lsCmd)
+}
+
+func runLs(cmd *cobra.Command, args []string) error {
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	tracker := usage.NewTracker(prof.ClaudeDir())
+	listed := []lsSession{}
+	projects := map[string]usage.Usage{}
+	var total, profileTotal usage.Usage
+	for i := range state.Sessions {
+		s := &state.Sessions[i]
+		var u *usage.Usage
+		if s.Type == session.TypeClaude {
+			su := tracker.SessionUsage(s)
+			u = &su
+			profileTotal.Add(su)
+		}
+		if !hasAllTags(s, tags) {
+			continue
+		}
+		ls := lsSession{
+			ID:      s.ID,
+			Project: s.Project,
+			Name:    s.DisplayName(),
+			Type:    string(s.Type),
+			Status:  s.Status,
+			Dir:     s.Dir,
+			Branch:  s.WorktreeBranch,
+			Tags:    s.Tags,
+		}
+		if u != nil {
+			ls.Type = "claude"
+			ls.Usage = u
+			total.Add(*u)
+			pu := projects[s.Project]
+			pu.Add(*u)
+			projects[s.Project] = pu
+		}
+		listed = append(listed, ls)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(lsOutput{Sessions: listed, Projects: projects, Total: total, Profile: profileTotal})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPROJECT\tNAME\tSTATUS\tCOST\tTAGS")
+	for _, ls := range listed {
+		cost := "-"
+		if ls.Usage != nil {
+			cost = ls.Usage.CostLabel()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			shortID(ls.ID), ls.Project, ls.Name, ls.Status, cost, strings.Join(ls.Tags, ","))
+	}
+	fmt.Fprintf(w, "\t\t\ttotal\t%s\t\n", total.CostLabel())
+	if len(tags) > 0 {
+		fmt.Fprintf(w, "\t\t\tprofile total\t%s\t\n", profileTotal.CostLabel())
+	}
+	return w.Flush()
+}
+
+// lsOutput is the `herd ls --json` document. Projects and Total cover the
+// listed sessions; Profile covers every session of the profile, whatever
+// --tag lists.
+type lsOutput struct {
+	Sessions []lsSession            `json:"sessions"`
+	Projects map[string]usage.Usage `json:"projects"` // by project name
+	Total    usage.Usage            `json:"total"`
+	Profile  usage.Usage            `json:"profile"`
+}
+
+type lsSession struct {
+	ID      string         `json:"id"`
+	Project string         `json:"project"`
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Status  session.Status `json:"status"`
+	Dir     string         `json:"dir"`
+	Branch  string         `json:"branch,omitempty"`
+	Tags    []string       `json:"tags,omitempty"`
+	Usage   *usage.Usage   `json:"usage,omitempty"` // Claude sessions only
+}
+
+// hasAllTags reports whether s carries every tag in tags.
+func hasAllTags(s *session.Session, tags []string) bool {
+	for _, t := range tags {
+		if !s.HasTag(strings.TrimLeft(t, "#")) {
+			return false
+		}
+	}
+	return true
+}
+
+// shortID abbreviates a session UUID for display.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allenan/herd/internal/control"
+	"github.com/allenan/herd/internal/overview"
+	"github.com/allenan/herd/internal/profile"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/allenan/herd/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var overviewCmd = &cobra.Command{
+	Use:   "overview",
+	Short: "Show sessions needing attention across all profiles",
+	Long: `List the sessions waiting on you in every profile whose tmux server is
+running: waiting for input first, then plans ready for review, then
+finished, longest waiting on top. Press a to list all sessions instead.
+
+Enter switches to the session. Inside herd, a session of another profile
+replaces the current herd with that profile's; outside tmux, herd attaches
+to it.`,
+	Args: cobra.NoArgs,
+	RunE: runOverview,
+}
+
+var popupOverviewCmd = &cobra.Command{
+	Use:    "popup-overview",
+	Short:  "Run the cross-profile overview popup (internal)",
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runOverviewTUI(profileName, true)
+	},
+}
+
+func init() {
+	overviewCmd.Flags().Bool("json", false, "print the sessions as JSON instead")
+	overviewCmd.Flags().BoolP("all", "a", false, "with --json, include sessions not needing attention")
+	rootCmd.AddCommand(overviewCmd, popupOverviewCmd)
+}
+
+// overviewItem is a session as printed by herd overview --json.
+type overviewItem struct {
+	Profile   string `json:"profile"`
+	ID        string `json:"id"`
+	Project   string `json:"project"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Since     string `json:"since,omitempty"`
+	Attention bool   `json:"needs_attention"`
+}
+
+func runOverview(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	all, _ := cmd.Flags().GetBool("all")
+
+	if asJSON {
+		items, err := overview.Collect(all)
+		if err != nil {
+			return err
+		}
+		out := []overviewItem{}
+		for _, it := range items {
+			s := &it.Session
+			oi := overviewItem{
+				Profile:   it.ProfileLabel(),
+				ID:        s.ID,
+				Project:   s.Project,
+				Name:      s.DisplayName(),
+				Status:    string(s.Status),
+				Attention: overview.NeedsAttention(s),
+			}
+			if since := s.StatusSince(); !since.IsZero() {
+				oi.Since = since.Format(time.RFC3339)
+			}
+			out = append(out, oi)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	current, inside := currentProfile()
+	return runOverviewTUI(current, inside)
+}
+
+// currentProfile finds the profile whose herd this command runs in, by
+// the tmux socket in $TMUX. inside is false outside herd.
+func currentProfile() (name string, inside bool) {
+	if !htmux.IsInsideHerd() || outerTmux == "" {
+		return "", false
+	}
+	socket, _, _ := strings.Cut(outerTmux, ",")
+	profiles, _ := profile.List()
+	for _, p := range profiles {
+		if p.SocketPath() == socket {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// runOverviewTUI runs the overview and switches to the session picked in
+// it, if any. current is the profile of the herd it runs inside, when
+// inside is set.
+func runOverviewTUI(current string, inside bool) error {
+	model := tui.NewOverviewModel(overview.Collect, current)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("overview error: %w", err)
+	}
+	it, ok := final.(tui.OverviewModel).Chosen()
+	if !ok {
+		return nil
+	}
+	return switchAcrossProfiles(it, current, inside)
+}
+
+// switchAcrossProfiles shows the item's session in its profile's herd and
+// brings that herd to the user's terminal.
+func switchAcrossProfiles(it overview.Item, current string, inside bool) error {
+	target, err := profile.Resolve(it.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	req := control.Request{Action: control.ActionSwitch, SessionID: it.Session.ID}
+	if err := control.Send(target.ControlSocketPath(), req); err != nil {
+		return err
+	}
+	if inside && it.Profile == current {
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+	argv := []string{exe}
+	if it.Profile != "" {
+		argv = append(argv, "--profile", it.Profile)
+	}
+	if inside {
+		cur, err := profile.Resolve(current)
+		if err != nil {
+			return fmt.Errorf("failed to resolve profile: %w", err)
+		}
+		return htmux.ReplaceClients(cur.SocketPath(), cur.TmuxSessionName(), argv)
+	}
+	if htmux.IsInsideHerd() {
+		return fmt.Errorf("switched %s, but can't attach to profile %s from inside herd",
+			it.Session.DisplayName(), it.ProfileLabel())
+	}
+	return syscall.Exec(exe, argv, os.Environ())
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/allenan/herd/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var popupGrepCmd = &cobra.Command{
+	Use:    "popup-grep",
+	Short:  "Run the output search popup (internal)",
+	Hidden: true,
+	RunE:   runPopupGrep,
+}
+
+func init() {
+	popupGrepCmd.Flags().String("result-path", "", "path for result file")
+	rootCmd.AddCommand(popupGrepCmd)
+}
+
+func runPopupGrep(cmd *cobra.Command, args []string) error {
+	resultPath, _ := cmd.Flags().GetString("result-path")
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	htmux.Init(prof)
+	if resultPath == "" {
+		resultPath = prof.PopupResultPath()
+	}
+
+	search := func(pattern string) ([]htmux.Match, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		state, err := session.LoadState(prof.StatePath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load state: %w", err)
+		}
+		return htmux.Grep(state.Sessions, prof.OutputLogDir(), re), nil
+	}
+
+	model := tui.NewGrepModel(search, resultPath)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("popup error: %w", err)
+	}
+
+	// If the popup exited without writing a result (user pressed Esc),
+	// write a canceled result so the sidebar stops polling.
+	tui.WriteCanceledResult(resultPath)
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var popupPaletteCmd = &cobra.Command{
+	Use:    "popup-palette",
+	Short:  "Run the command palette popup (internal)",
+	Hidden: true,
+	RunE:   runPopupPalette,
+}
+
+func init() {
+	popupPaletteCmd.Flags().String("result-path", "", "path for result file")
+	rootCmd.AddCommand(popupPaletteCmd)
+}
+
+func runPopupPalette(cmd *cobra.Command, args []string) error {
+	resultPath, _ := cmd.Flags().GetString("result-path")
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	if resultPath == "" {
+		resultPath = prof.PopupResultPath()
+	}
+
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	model := tui.NewPaletteModel(state, resultPath)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("popup error: %w", err)
+	}
+
+	// If the popup exited without writing a result (user pressed Esc),
+	// write a canceled result so the sidebar stops polling.
+	tui.WriteCanceledResult(resultPath)
+
+	return nil
+}
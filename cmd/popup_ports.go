@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/allenan/herd/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var popupPortsCmd = &cobra.Command{
+	Use:    "popup-ports",
+	Short:  "Run the listening ports popup (internal)",
+	Hidden: true,
+	RunE:   runPopupPorts,
+}
+
+func init() {
+	popupPortsCmd.Flags().String("session", "", "terminal session ID")
+	rootCmd.AddCommand(popupPortsCmd)
+}
+
+func runPopupPorts(cmd *cobra.Command, args []string) error {
+	sessionID, _ := cmd.Flags().GetString("session")
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	htmux.Init(prof)
+
+	state, err := session.LoadState(prof.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	sess := state.FindByID(sessionID)
+	if sess == nil {
+		return fmt.Errorf("no session with ID %q", sessionID)
+	}
+
+	model := tui.NewPortsModel(sess, htmux.CopyToClipboard)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("popup error: %w", err)
+	}
+	return nil
+}
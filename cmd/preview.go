@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allenan/herd/internal/profile"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// previewInterval is how often the preview pane re-captures its target.
+const previewInterval = 500 * time.Millisecond
+
+var previewCmd = &cobra.Command{
+	Use:    "preview",
+	Short:  "Show a live tail of a session pane (internal)",
+	Hidden: true,
+	RunE:   runPreview,
+}
+
+func init() {
+	previewCmd.Flags().String("pane", "", "tmux pane ID to preview")
+	previewCmd.Flags().String("title", "", "label shown above the preview")
+	previewCmd.Flags().Int("lines", 0, "number of lines to show (0 = fit pane)")
+	rootCmd.AddCommand(previewCmd)
+}
+
+func runPreview(cmd *cobra.Command, args []string) error {
+	paneID, _ := cmd.Flags().GetString("pane")
+	title, _ := cmd.Flags().GetString("title")
+	lines, _ := cmd.Flags().GetInt("lines")
+
+	if paneID == "" {
+		return fmt.Errorf("--pane is required")
+	}
+
+	prof, err := profile.Resolve(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile: %w", err)
+	}
+	htmux.Init(prof)
+
+	// Hide cursor and swallow keystrokes, like the placeholder. MakeRaw
+	// disables OPOST, so previewFrame must use \r\n.
+	fmt.Print("\033[?25l")
+	if oldState, err := term.MakeRaw(int(os.Stdin.Fd())); err == nil {
+		defer term.Restore(int(os.Stdin.Fd()), oldState)
+	}
+
+	ticker := time.NewTicker(previewInterval)
+	defer ticker.Stop()
+	var last string
+	for {
+		content, err := htmux.CapturePaneContent(paneID)
+		if err != nil {
+			content = "(session is gone)"
+		}
+		// Only redraw when something changed to avoid flicker
+		if frame := previewFrame(title, content, lines); frame != last {
+			fmt.Print(frame)
+			last = frame
+		}
+		<-ticker.C
+	}
+}
+
+// previewFrame renders a dim header followed by the last lines of content
+// that fit in the pane.
+func previewFrame(title, content string, maxLines int) string {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w, h = 80, 24
+	}
+
+	const (
+		colorHeader = "\033[38;5;245m"
+		reset       = "\033[0m"
+	)
+
+	body := strings.Split(strings.TrimRight(content, "\n "), "\n")
+	avail := h - 1 // header line
+	if maxLines > 0 && maxLines < avail {
+		avail = maxLines
+	}
+	if avail < 0 {
+		avail = 0
+	}
+	if len(body) > avail {
+		body = body[len(body)-avail:]
+	}
+
+	var buf strings.Builder
+	buf.WriteString("\033[2J\033[H")
+	buf.WriteString(colorHeader)
+	buf.WriteString(clipLine("preview · "+title, w))
+	buf.WriteString(reset)
+	for _, line := range body {
+		buf.WriteString("\r\n")
+		buf.WriteString(clipLine(line, w))
+	}
+	return buf.String()
+}
+
+// clipLine truncates a line to at most w runes.
+func clipLine(s string, w int) string {
+	runes := []rune(s)
+	if w > 0 && len(runes) > w {
+		return string(runes[:w])
+	}
+	return s
+}
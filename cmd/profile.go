@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List, create, copy and remove profiles",
+	Long: `Manage profiles, the isolated herd environments selected with --profile.
+Each has its own state, tmux server and Claude config directory. The
+default profile is called "default" here.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles with their server state and session count",
+	Args:    cobra.NoArgs,
+	RunE:    runProfileList,
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileCreate,
+}
+
+var profileRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Stop a profile's tmux server and delete the profile",
+	Long: `Stop a profile's tmux server and sidebar, then delete its directory with
+its state and config. With --keep-state only the server is stopped, so
+running herd with the profile again starts it over with its sessions.
+The profile's Claude config directory is never deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileRm,
+}
+
+var profileCpCmd = &cobra.Command{
+	Use:   "cp <source> <name>",
+	Short: "Create a profile with the config of another",
+	Long: `Create a profile with a copy of another profile's config. Sessions are
+not copied. The new profile gets its own Claude config directory unless
+the source set one explicitly.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProfileCp,
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Print a profile's resolved paths and settings",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runProfileShow,
+}
+
+func init() {
+	profileListCmd.Flags().Bool("json", false, "print profiles as JSON")
+	profileCreateCmd.Flags().String("claude-config-dir", "", "Claude config directory (default ~/.claude-<name>)")
+	profileRmCmd.Flags().BoolP("yes", "y", false, "don't ask for confirmation")
+	profileRmCmd.Flags().Bool("keep-state", false, "only stop the server, keep state and config")
+	profileShowCmd.Flags().Bool("json", false, "print as JSON")
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileRmCmd, profileCpCmd, profileShowCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+// profileArg maps a profile name given on the command line to a profile
+// name: "default" is the default profile, unless a profile is really
+// called that.
+func profileArg(name string) string {
+	if name == "default" && !profile.Exists("default") {
+		return ""
+	}
+	return name
+}
+
+// profileLabel is how a profile is named in output.
+func profileLabel(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// profileInfo is a profile as listed and shown.
+type profileInfo struct {
+	Name            string   `json:"name"`
+	Current         bool     `json:"current,omitempty"` // selected with --profile
+	ServerRunning   bool     `json:"server_running"`
+	Sessions        int      `json:"sessions"`
+	ClaudeConfigDir string   `json:"claude_config_dir"`
+	BaseDir         string   `json:"base_dir"`
+	ConfigPath      string   `json:"config_path"`
+	StatePath       string   `json:"state_path"`
+	SocketPath      string   `json:"socket_path"`
+	ControlSocket   string   `json:"control_socket"`
+	TmuxSession     string   `json:"tmux_session"`
+	DebugLog        string   `json:"debug_log"`
+	OutputLogDir    string   `json:"output_log_dir"`
+	ExportDir       string   `json:"export_dir"`
+	Env             []string `json:"env,omitempty"` // names only, values may be secrets
+	PathPrefix      []string `json:"path_prefix,omitempty"`
+	DefaultDir      string   `json:"default_dir,omitempty"`
+	ClaudeArgs      []string `json:"claude_args,omitempty"`
+	AccentColor     string   `json:"accent_color,omitempty"`
+	Error           string   `json:"error,omitempty"` // config that fails to load
+}
+
+func describeProfile(p *profile.Profile) profileInfo {
+	info := profileInfo{Name: profileLabel(p.Name), Current: p.Name == profileName}
+	loaded, err := profile.Load(p.Name)
+	if err != nil {
+		info.Error = err.Error()
+	} else {
+		p = loaded
+	}
+	info.ServerRunning = htmux.ServerRunningAt(p.SocketPath())
+	if state, err := session.LoadState(p.StatePath()); err == nil {
+		info.Sessions = len(state.Sessions)
+	}
+	info.ClaudeConfigDir = p.ClaudeDir()
+	info.BaseDir = p.BaseDir
+	info.ConfigPath = p.ConfigPath()
+	info.StatePath = p.StatePath()
+	info.SocketPath = p.SocketPath()
+	info.ControlSocket = p.ControlSocketPath()
+	info.TmuxSession = p.TmuxSessionName()
+	info.DebugLog = p.LogPath()
+	info.OutputLogDir = p.OutputLogDir()
+	info.ExportDir = p.ExportDir()
+	for name := range p.Env {
+		info.Env = append(info.Env, name)
+	}
+	sort.Strings(info.Env)
+	info.PathPrefix = p.PathPrefix
+	info.DefaultDir = p.DefaultDir
+	info.ClaudeArgs = p.ClaudeArgs
+	info.AccentColor = p.AccentColor
+	return info
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	profiles, err := profile.List()
+	if err != nil {
+		return err
+	}
+	infos := []profileInfo{}
+	for _, p := range profiles {
+		infos = append(infos, describeProfile(p))
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(infos)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tSERVER\tSESSIONS\tCLAUDE CONFIG")
+	for _, info := range infos {
+		marker := " "
+		if info.Current {
+			marker = "*"
+		}
+		server := "stopped"
+		if info.ServerRunning {
+			server = "running"
+		}
+		claude := shortenHomePath(info.ClaudeConfigDir)
+		if info.Error != "" {
+			claude = "invalid config: " + info.Error
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%d\t%s\n", marker, info.Name, server, info.Sessions, claude)
+	}
+	return w.Flush()
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) error {
+	claudeDir, _ := cmd.Flags().GetString("claude-config-dir")
+
+	name := args[0]
+	if name == "default" {
+		return fmt.Errorf("the default profile always exists")
+	}
+	if profile.Exists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	p, err := profile.Resolve(name)
+	if err != nil {
+		return err
+	}
+	if claudeDir != "" {
+		p.ClaudeConfigDir = claudeDir
+		if err := p.SaveDefaultConfig(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Created profile %s in %s\nClaude config: %s\nStart it with: herd --profile %s\n",
+		name, p.BaseDir, p.ClaudeDir(), name)
+	return nil
+}
+
+func runProfileRm(cmd *cobra.Command, args []string) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	keepState, _ := cmd.Flags().GetBool("keep-state")
+
+	name := profileArg(args[0])
+	if name == "" {
+		return fmt.Errorf("the default profile can't be removed; use herd kill to reset it")
+	}
+	if !profile.Exists(name) {
+		return fmt.Errorf("no profile %q", name)
+	}
+	p, err := profile.Load(name)
+	if err != nil {
+		// A broken config shouldn't stop its profile from being removed
+		profiles, _ := profile.List()
+		for _, listed := range profiles {
+			if listed.Name == name {
+				p = listed
+			}
+		}
+		if p == nil {
+			return err
+		}
+	}
+
+	if !yes {
+		action := fmt.Sprintf("delete %s", p.BaseDir)
+		if keepState {
+			action = "keep its state"
+		}
+		fmt.Printf("Stop profile %s and %s? [y/N] ", name, action)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	stopProfile(p)
+	if keepState {
+		fmt.Printf("Stopped profile %s. State kept in %s\n", name, p.BaseDir)
+		return nil
+	}
+	if err := os.RemoveAll(p.BaseDir); err != nil {
+		return fmt.Errorf("failed to remove profile directory %s: %w", p.BaseDir, err)
+	}
+	fmt.Printf("Removed profile %s. Its Claude config in %s was kept.\n", name, shortenHomePath(p.ClaudeDir()))
+	return nil
+}
+
+func runProfileCp(cmd *cobra.Command, args []string) error {
+	srcName := profileArg(args[0])
+	if !profile.Exists(srcName) {
+		return fmt.Errorf("no profile %q", args[0])
+	}
+	src, err := profile.Resolve(srcName)
+	if err != nil {
+		return err
+	}
+	dst, err := profile.Copy(src, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created profile %s from %s in %s\nClaude config: %s\n",
+		dst.Name, profileLabel(src.Name), dst.BaseDir, dst.ClaudeDir())
+	return nil
+}
+
+func runProfileShow(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	name := profileName
+	if len(args) == 1 {
+		name = profileArg(args[0])
+	}
+	if !profile.Exists(name) {
+		return fmt.Errorf("no profile %q", name)
+	}
+	info := describeProfile(&profile.Profile{Name: name})
+	if info.Error != "" {
+		return fmt.Errorf("%s", info.Error)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	server := "stopped"
+	if info.ServerRunning {
+		server = "running"
+	}
+	rows := [][2]string{
+		{"name", info.Name},
+		{"server", server},
+		{"sessions", fmt.Sprint(info.Sessions)},
+		{"dir", info.BaseDir},
+		{"config", info.ConfigPath},
+		{"state", info.StatePath},
+		{"claude config", info.ClaudeConfigDir},
+		{"tmux socket", info.SocketPath},
+		{"tmux session", info.TmuxSession},
+		{"control socket", info.ControlSocket},
+		{"debug log", info.DebugLog},
+		{"output logs", info.OutputLogDir},
+		{"exports", info.ExportDir},
+	}
+	for _, row := range [][2]string{
+		{"env", strings.Join(info.Env, " ")},
+		{"path prefix", strings.Join(info.PathPrefix, ":")},
+		{"default dir", info.DefaultDir},
+		{"claude flags", strings.Join(info.ClaudeArgs, " ")},
+		{"accent color", info.AccentColor},
+	} {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\n", row[0], row[1])
+	}
+	return w.Flush()
+}
+
+// shortenHomePath replaces the home directory prefix with ~.
+func shortenHomePath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, home+"/") {
+		return "~" + path[len(home):]
+	}
+	return path
+}
@@ -15,6 +15,9 @@ import (
 var sidebarFlag bool
 var profileName string
 
+// outerTmux is $TMUX as herd was started with, before Execute unsets it.
+var outerTmux string
+
 // Version is set at build time via ldflags.
 var Version = "dev"
 
@@ -39,6 +42,7 @@ func init() {
 func Execute() {
 	// Unset TMUX so herd works when launched from inside another tmux session.
 	// We use our own dedicated socket, so nesting is safe.
+	outerTmux = os.Getenv("TMUX")
 	os.Unsetenv("TMUX")
 
 	if err := rootCmd.Execute(); err != nil {
@@ -108,10 +112,15 @@ func runMain() error {
 		return fmt.Errorf("failed to start tmux server: %w", err)
 	}
 
-	htmux.ApplyEnv()
+	if err := htmux.BindKeys(prof.Keys); err != nil {
+		return fmt.Errorf("failed to bind keys: %w", err)
+	}
 
 	if !alreadyRunning {
 		// Fresh server — old session panes are gone, clear stale entries
+		for _, s := range state.Sessions {
+			state.Archive(s)
+		}
 		state.Sessions = nil
 		state.LastActiveSession = ""
 	}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/allenan/herd/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var serviceRunCmd = &cobra.Command{
+	Use:    "service-run <name>",
+	Short:  "Run a project service, restarting it when it crashes (internal)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		manifest, err := services.Load(dir)
+		if err != nil {
+			return err
+		}
+		svc, ok := manifest.Find(args[0])
+		if !ok {
+			return fmt.Errorf("no service %q in %s", args[0], dir)
+		}
+		cmd.SilenceUsage = true
+		return services.Run(dir, svc)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceRunCmd)
+}
@@ -5,11 +5,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/allenan/herd/internal/control"
 	"github.com/allenan/herd/internal/notify"
 	"github.com/allenan/herd/internal/profile"
 	"github.com/allenan/herd/internal/session"
 	htmux "github.com/allenan/herd/internal/tmux"
 	"github.com/allenan/herd/internal/tui"
+	"github.com/allenan/herd/internal/usage"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -32,14 +34,55 @@ func runSidebar() error {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
 
+	// Clicking a notification runs `herd switch <id>`
+	var clickCmd []string
+	if bin, err := os.Executable(); err == nil {
+		clickCmd = []string{bin}
+		if profileName != "" {
+			clickCmd = append(clickCmd, "--profile", profileName)
+		}
+		clickCmd = append(clickCmd, "switch")
+	}
+	notifier, err := notify.FromConfig(prof.NotificationSinks, clickCmd, profileName)
+	if err != nil {
+		return err
+	}
+
 	manager := htmux.NewManager(client, state, statePath)
-	manager.Notifier = notify.New()
+	manager.Notifier = notifier
+	manager.Usage = usage.NewTracker(prof.ClaudeDir())
+	manager.ContextWindow = prof.ContextWindow
+	manager.ContextWarnPercent = prof.ContextWarnPercent
+	manager.Rules = prof.Notifications
+	manager.SaveRules = prof.SaveNotifications
+	manager.PortBase = prof.WorktreePortBase
+	manager.LogDir = prof.OutputLogDir()
+	manager.LogOutput = prof.LogOutput
+	manager.ExportDir = prof.ExportDir()
+	// Other profiles' sessions can hold ports this one's want
+	if profiles, err := profile.List(); err == nil {
+		manager.Peers = map[string]*profile.Profile{}
+		for _, p := range profiles {
+			if p.Name == prof.Name {
+				continue
+			}
+			name := p.Name
+			if name == "" {
+				name = "default"
+			}
+			manager.Peers[name] = p
+		}
+	}
+	manager.KillStalePreviews()
 	manager.Reconcile()
 
-	// Default directory for new sessions: use the directory herd was launched from
-	defaultDir, err := os.Getwd()
-	if err != nil {
-		defaultDir = os.Getenv("HOME")
+	// Default directory for new sessions: the profile's, or the directory
+	// herd was launched from
+	defaultDir := prof.DefaultDir
+	if defaultDir == "" {
+		if defaultDir, err = os.Getwd(); err != nil {
+			defaultDir = os.Getenv("HOME")
+		}
 	}
 
 	// Allow overriding light/dark detection (OSC 11 can be unreliable inside tmux)
@@ -49,9 +92,21 @@ func runSidebar() error {
 	case "dark":
 		lipgloss.SetHasDarkBackground(true)
 	}
+	if prof.AccentColor != "" {
+		tui.SetAccentColor(prof.AccentColor)
+	}
 
 	app := tui.NewApp(manager, defaultDir, prof.Name)
 	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithReportFocus())
+
+	// Accept `herd ctl` requests from tmux key bindings. Non-fatal: the
+	// sidebar still works without it, only the global bindings don't.
+	if ln, err := control.Listen(prof.ControlSocketPath(), func(req control.Request) {
+		p.Send(tui.ControlMsg(req))
+	}); err == nil {
+		defer ln.Close()
+	}
+
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/allenan/herd/internal/control"
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var switchCmd = &cobra.Command{
+	Use:   "switch <session-id | herd://switch/<session-id>>",
+	Short: "Switch the running sidebar to a session",
+	Long: `Switch the running sidebar to a session, as if it had been selected with
+Enter. The ID may be shortened to any unique prefix (herd ls shows the first
+8 characters). Clicking a notification runs this command.
+
+A herd://switch/<session-id>?profile=<name> URL is accepted too, so herd can
+be registered as the handler of herd:// links in notifications.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, profName, err := parseSwitchArg(args[0])
+		if err != nil {
+			return err
+		}
+		// A stale or bogus link mustn't create the profile it names
+		if !profile.Exists(profName) {
+			return fmt.Errorf("no profile %q", profName)
+		}
+		prof, err := profile.Load(profName)
+		if err != nil {
+			return fmt.Errorf("failed to load profile: %w", err)
+		}
+		state, err := session.LoadState(prof.StatePath())
+		if err != nil {
+			return fmt.Errorf("failed to load state: %w", err)
+		}
+		s, err := state.FindByIDPrefix(id)
+		if err != nil {
+			return err
+		}
+		return control.Send(prof.ControlSocketPath(), control.Request{
+			Action:    control.ActionSwitch,
+			SessionID: s.ID,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+// parseSwitchArg returns the session ID and profile of a herd switch
+// argument: a plain ID in the --profile profile, or a herd:// URL, whose
+// profile parameter wins.
+func parseSwitchArg(arg string) (id, prof string, err error) {
+	if !strings.HasPrefix(arg, "herd://") {
+		return arg, profileName, nil
+	}
+	u, err := url.Parse(arg)
+	if err != nil || u.Host != "switch" {
+		return "", "", fmt.Errorf("invalid herd URL %q: want herd://switch/<session-id>", arg)
+	}
+	id = strings.TrimPrefix(u.Path, "/")
+	if id == "" {
+		return "", "", fmt.Errorf("invalid herd URL %q: missing session ID", arg)
+	}
+	prof = profileName
+	if p := u.Query().Get("profile"); p != "" {
+		prof = p
+	}
+	return id, prof, nil
+}
@@ -0,0 +1,107 @@
+// Package control lets short-lived herd commands (typically run from tmux
+// key bindings) drive the running sidebar over a unix socket.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// sendTimeout bounds how long a client waits for the sidebar to answer.
+const sendTimeout = 2 * time.Second
+
+// Actions understood by the sidebar.
+const (
+	ActionLast     = "last"     // previously visited session (MRU)
+	ActionInput    = "input"    // next session waiting for input
+	ActionDone     = "done"     // next session with a finished task or ready plan
+	ActionNext     = "next"     // next session in sidebar order
+	ActionPrev     = "prev"     // previous session in sidebar order
+	ActionNew      = "new"      // new Claude session in the active project
+	ActionTerminal = "terminal" // new terminal in the active project
+	ActionKill     = "kill"     // kill the active session and its paired terminals
+	ActionSwitch   = "switch"   // switch to Request.SessionID (`herd switch`)
+)
+
+// Actions lists the actions `herd ctl` accepts, for validation and help
+// text. ActionSwitch needs a session ID and has its own command.
+var Actions = []string{
+	ActionLast, ActionInput, ActionDone,
+	ActionNext, ActionPrev,
+	ActionNew, ActionTerminal, ActionKill,
+}
+
+// Valid reports whether action is one of Actions.
+func Valid(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Request asks the sidebar to perform an action.
+type Request struct {
+	Action    string `json:"action"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// Listen serves requests on a unix socket at path, calling handle for each.
+// A stale socket left by a previous sidebar is replaced. Close the returned
+// listener to stop serving.
+func Listen(path string, handle func(Request)) (net.Listener, error) {
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn, handle)
+		}
+	}()
+	return ln, nil
+}
+
+func serve(conn net.Conn, handle func(Request)) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(sendTimeout))
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		fmt.Fprintf(conn, "error: %v\n", err)
+		return
+	}
+	handle(req)
+	fmt.Fprintln(conn, "ok")
+}
+
+// Send delivers a request to the sidebar listening at path.
+func Send(path string, req Request) error {
+	conn, err := net.DialTimeout("unix", path, sendTimeout)
+	if err != nil {
+		return fmt.Errorf("herd sidebar is not running: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(sendTimeout))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("no reply from sidebar: %w", err)
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return fmt.Errorf("sidebar: %s", reply)
+	}
+	return nil
+}
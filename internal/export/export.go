@@ -0,0 +1,328 @@
+// Package export renders a session's conversation, from Claude Code's
+// JSONL transcripts or a terminal's scrollback, as Markdown or standalone
+// HTML for sharing.
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Role says who an entry comes from.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleOutput    Role = "output" // raw terminal output
+)
+
+// Doc is a conversation ready to be rendered.
+type Doc struct {
+	Title   string
+	Meta    []Field // shown under the title
+	Entries []Entry
+}
+
+// Field is a labeled value, e.g. project or branch.
+type Field struct {
+	Name, Value string
+}
+
+// Entry is a message, or a tool call made by the assistant.
+type Entry struct {
+	Role Role
+	Time time.Time
+	Text string    // prose, Markdown for the assistant
+	Tool *ToolCall // set for tool calls, with Text empty
+}
+
+// ToolCall is a tool the assistant used, with its result.
+type ToolCall struct {
+	Name    string
+	Summary string // what it worked on: a file path, a command, …
+	Input   string // the command or pretty-printed input, "" when Diff says it all
+	Diff    string // unified-style diff for edits and writes
+	Output  string
+	IsError bool
+}
+
+// maxOutputLines caps the tool output kept per call; file reads and
+// searches can be huge.
+const maxOutputLines = 80
+
+// transcriptLine is the subset of a Claude Code transcript line needed to
+// rebuild the conversation.
+type transcriptLine struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	IsSidechain bool      `json:"isSidechain"` // subagent traffic
+	IsMeta      bool      `json:"isMeta"`      // injected by Claude Code, not typed
+	Message     struct {
+		Content json.RawMessage `json:"content"` // a string, or content blocks
+	} `json:"message"`
+}
+
+// block is a message content block.
+type block struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"` // tool result: a string, or text blocks
+	IsError   bool            `json:"is_error"`
+}
+
+// FromTranscripts rebuilds the main conversation of one or more
+// transcripts, in order. Thinking, subagent traffic and messages Claude
+// Code injected are left out.
+func FromTranscripts(paths []string) (*Doc, error) {
+	d := &Doc{}
+	calls := map[string]*ToolCall{} // by tool_use ID, for their results
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for sc.Scan() {
+			var line transcriptLine
+			if json.Unmarshal(sc.Bytes(), &line) != nil || line.IsSidechain || line.IsMeta {
+				continue
+			}
+			switch line.Type {
+			case "user":
+				d.addUser(line, calls)
+			case "assistant":
+				d.addAssistant(line, calls)
+			}
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// FromScrollback wraps a terminal's captured output.
+func FromScrollback(text string) *Doc {
+	return &Doc{Entries: []Entry{{Role: RoleOutput, Text: strings.TrimRight(text, "\n")}}}
+}
+
+func (d *Doc) addUser(line transcriptLine, calls map[string]*ToolCall) {
+	var text string
+	if json.Unmarshal(line.Message.Content, &text) == nil {
+		if text, ok := userText(text); ok {
+			d.Entries = append(d.Entries, Entry{Role: RoleUser, Time: line.Timestamp, Text: text})
+		}
+		return
+	}
+	var blocks []block
+	if json.Unmarshal(line.Message.Content, &blocks) != nil {
+		return
+	}
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			if text, ok := userText(b.Text); ok {
+				d.Entries = append(d.Entries, Entry{Role: RoleUser, Time: line.Timestamp, Text: text})
+			}
+		case "tool_result":
+			if call := calls[b.ToolUseID]; call != nil {
+				call.Output = clip(resultText(b.Content), maxOutputLines)
+				call.IsError = b.IsError
+			}
+		}
+	}
+}
+
+func (d *Doc) addAssistant(line transcriptLine, calls map[string]*ToolCall) {
+	var blocks []block
+	if json.Unmarshal(line.Message.Content, &blocks) != nil {
+		return
+	}
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			if strings.TrimSpace(b.Text) != "" {
+				d.Entries = append(d.Entries, Entry{Role: RoleAssistant, Time: line.Timestamp, Text: b.Text})
+			}
+		case "tool_use":
+			call := toolCall(b.Name, b.Input)
+			calls[b.ID] = call
+			d.Entries = append(d.Entries, Entry{Role: RoleAssistant, Time: line.Timestamp, Tool: call})
+		}
+	}
+}
+
+// userText returns what the user typed, turning slash command markup into
+// the command and dropping command output and reminders.
+func userText(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return "", false
+	case strings.HasPrefix(s, "<command-name>"):
+		name := between(s, "<command-name>", "</command-name>")
+		args := between(s, "<command-args>", "</command-args>")
+		return strings.TrimSpace(name + " " + args), name != ""
+	case strings.HasPrefix(s, "<local-command-stdout>"), strings.HasPrefix(s, "<system-reminder>"):
+		return "", false
+	}
+	return s, true
+}
+
+// between returns the text between the first start and the following end.
+func between(s, start, end string) string {
+	_, rest, ok := strings.Cut(s, start)
+	if !ok {
+		return ""
+	}
+	inner, _, _ := strings.Cut(rest, end)
+	return inner
+}
+
+// resultText flattens a tool result's content to text.
+func resultText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var blocks []block
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// clip keeps the first n lines of s, noting how many were dropped.
+func clip(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n… %d more lines", len(lines)-n)
+}
+
+// toolInput holds the input fields of the tools rendered specially.
+type toolInput struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	FilePath    string `json:"file_path"`
+	Path        string `json:"path"`
+	Pattern     string `json:"pattern"`
+	OldString   string `json:"old_string"`
+	NewString   string `json:"new_string"`
+	Content     string `json:"content"`
+	Edits       []struct {
+		OldString string `json:"old_string"`
+		NewString string `json:"new_string"`
+	} `json:"edits"`
+}
+
+// toolCall describes a tool use: edits and writes as diffs, commands as
+// themselves and anything else by its JSON input.
+func toolCall(name string, raw json.RawMessage) *ToolCall {
+	call := &ToolCall{Name: name, Summary: name}
+	var in toolInput
+	json.Unmarshal(raw, &in)
+
+	switch name {
+	case "Bash":
+		call.Summary = firstLine(in.Command)
+		if in.Description != "" {
+			call.Summary = in.Description
+		}
+		call.Input = in.Command
+		return call
+	case "Edit":
+		call.Summary = in.FilePath
+		call.Diff = diff(in.OldString, in.NewString)
+		return call
+	case "MultiEdit":
+		call.Summary = in.FilePath
+		var hunks []string
+		for _, e := range in.Edits {
+			hunks = append(hunks, diff(e.OldString, e.NewString))
+		}
+		call.Diff = strings.Join(hunks, "\n@@\n")
+		return call
+	case "Write":
+		call.Summary = in.FilePath
+		call.Diff = diff("", in.Content)
+		return call
+	}
+
+	for _, s := range []string{in.FilePath, in.Path, in.Pattern, in.Command} {
+		if s != "" {
+			call.Summary = firstLine(s)
+			break
+		}
+	}
+	var pretty strings.Builder
+	var v any
+	if json.Unmarshal(raw, &v) == nil {
+		enc := json.NewEncoder(&pretty)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+	}
+	call.Input = strings.TrimRight(pretty.String(), "\n")
+	return call
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// diff renders the change from before to after as unified diff lines,
+// keeping the lines both share at the start and end as context.
+func diff(before, after string) string {
+	a, b := splitLines(before), splitLines(after)
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var out []string
+	for _, l := range a[:pre] {
+		out = append(out, " "+l)
+	}
+	for _, l := range a[pre : len(a)-suf] {
+		out = append(out, "-"+l)
+	}
+	for _, l := range b[pre : len(b)-suf] {
+		out = append(out, "+"+l)
+	}
+	for _, l := range a[len(a)-suf:] {
+		out = append(out, " "+l)
+	}
+	return strings.Join(out, "\n")
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,194 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromTranscripts(t *testing.T) {
+	doc, err := FromTranscripts([]string{
+		filepath.Join("testdata", "first.jsonl"),
+		filepath.Join("testdata", "second.jsonl"),
+	})
+	if err != nil {
+		t.Fatalf("FromTranscripts: %v", err)
+	}
+
+	want := []Entry{
+		{Role: RoleUser, Text: "Fix the login bug"},
+		{Role: RoleAssistant, Text: "Let me run the tests."},
+		{Role: RoleAssistant, Tool: &ToolCall{
+			Name:    "Bash",
+			Summary: "Run tests",
+			Input:   "go test ./...\ngo vet ./...",
+			Output:  "FAIL login",
+		}},
+		{Role: RoleAssistant, Tool: &ToolCall{
+			Name:    "Edit",
+			Summary: "auth.go",
+			Diff:    " a\n-b\n+B\n c",
+			Output:  "file changed\nread it again",
+			IsError: true,
+		}},
+		// The result comes from the next transcript
+		{Role: RoleAssistant, Tool: &ToolCall{
+			Name:    "Read",
+			Summary: "auth_test.go",
+			Input:   "{\n  \"file_path\": \"auth_test.go\"\n}",
+			Output:  "package auth",
+		}},
+		{Role: RoleUser, Text: "/model opus"},
+		{Role: RoleUser, Text: "ship it"},
+		{Role: RoleAssistant, Text: "Done."},
+	}
+	if len(doc.Entries) != len(want) {
+		for _, e := range doc.Entries {
+			t.Logf("%s %q %+v", e.Role, e.Text, e.Tool)
+		}
+		t.Fatalf("got %d entries, want %d", len(doc.Entries), len(want))
+	}
+	for i, w := range want {
+		got := doc.Entries[i]
+		if got.Role != w.Role || got.Text != w.Text {
+			t.Errorf("entry %d = %s %q, want %s %q", i, got.Role, got.Text, w.Role, w.Text)
+		}
+		if (got.Tool == nil) != (w.Tool == nil) {
+			t.Errorf("entry %d tool = %+v, want %+v", i, got.Tool, w.Tool)
+		} else if w.Tool != nil && *got.Tool != *w.Tool {
+			t.Errorf("entry %d tool = %+v, want %+v", i, *got.Tool, *w.Tool)
+		}
+	}
+	if first := doc.Entries[0].Time; !first.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("first entry time = %v, want 2026-03-01 10:00:00 UTC", first)
+	}
+}
+
+func TestFromTranscriptsMissingFile(t *testing.T) {
+	if _, err := FromTranscripts([]string{filepath.Join("testdata", "missing.jsonl")}); err == nil {
+		t.Error("FromTranscripts(missing) = nil error, want an error")
+	}
+}
+
+func TestFromTranscriptsClipsOutput(t *testing.T) {
+	dir := t.TempDir()
+	output := strings.Repeat("line\\n", maxOutputLines+5)
+	path := filepath.Join(dir, "long.jsonl")
+	writeFile(t, path,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Grep","input":{"pattern":"TODO"}}]}}`+"\n"+
+			`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"`+output+`"}]}}`+"\n")
+
+	doc, err := FromTranscripts([]string{path})
+	if err != nil {
+		t.Fatalf("FromTranscripts: %v", err)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].Tool == nil {
+		t.Fatalf("got %+v, want one tool call", doc.Entries)
+	}
+	call := doc.Entries[0].Tool
+	if call.Summary != "TODO" {
+		t.Errorf("summary = %q, want %q", call.Summary, "TODO")
+	}
+	lines := strings.Split(call.Output, "\n")
+	if len(lines) != maxOutputLines+1 || lines[maxOutputLines] != "… 5 more lines" {
+		t.Errorf("output has %d lines ending %q, want %d ending %q",
+			len(lines), lines[len(lines)-1], maxOutputLines+1, "… 5 more lines")
+	}
+}
+
+func TestUserText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"  fix it\n", "fix it", true},
+		{"", "", false},
+		{"   \n", "", false},
+		{"<command-name>/clear</command-name>\n<command-args></command-args>", "/clear", true},
+		{"<command-name>/model</command-name>\n<command-args>sonnet</command-args>", "/model sonnet", true},
+		{"<local-command-stdout>ok</local-command-stdout>", "", false},
+		{"<system-reminder>be brief</system-reminder>", "", false},
+		{"what does <system-reminder> mean?", "what does <system-reminder> mean?", true},
+	}
+	for _, tt := range tests {
+		got, ok := userText(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("userText(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{"new file", "", "a\nb\n", "+a\n+b"},
+		{"emptied", "a\n", "", "-a"},
+		{"changed middle", "a\nb\nc", "a\nx\ny\nc", " a\n-b\n+x\n+y\n c"},
+		{"appended", "a\nb", "a\nb\nc", " a\n b\n+c"},
+		{"prepended", "b\nc", "a\nb\nc", "+a\n b\n c"},
+		{"unchanged", "a\nb", "a\nb", " a\n b"},
+		{"repeated lines", "a\na", "a\na\na", " a\n a\n+a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.before, tt.after); got != tt.want {
+				t.Errorf("diff(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFenced(t *testing.T) {
+	tests := []struct {
+		lang, text string
+		want       string
+	}{
+		{"sh", "ls", "```sh\nls\n```\n"},
+		{"", "plain", "```\nplain\n```\n"},
+		{"", "use ``` to fence", "````\nuse ``` to fence\n````\n"},
+		{"md", "````\nx\n````", "`````md\n````\nx\n````\n`````\n"},
+	}
+	for _, tt := range tests {
+		if got := fenced(tt.lang, tt.text); got != tt.want {
+			t.Errorf("fenced(%q, %q) = %q, want %q", tt.lang, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	doc, err := FromTranscripts([]string{filepath.Join("testdata", "first.jsonl")})
+	if err != nil {
+		t.Fatalf("FromTranscripts: %v", err)
+	}
+	doc.Title = "login"
+	doc.Meta = []Field{{Name: "project", Value: "web"}}
+
+	var b strings.Builder
+	if err := doc.Markdown(&b); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	for _, want := range []string{
+		"# login\n\n- **project:** web\n",
+		"## User\n\n> Fix the login bug\n",
+		"## Claude\n\nLet me run the tests.\n",
+		"<summary>Bash Run tests</summary>\n\n```sh\ngo test ./...\ngo vet ./...\n```\n\n```\nFAIL login\n```\n",
+		"<summary>Edit auth.go (failed)</summary>\n\n```diff\n a\n-b\n+B\n c\n```\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Markdown is missing %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
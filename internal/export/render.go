@@ -0,0 +1,167 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Markdown writes d as GitHub-flavored Markdown. Tool calls go in
+// collapsed <details> sections.
+func (d *Doc) Markdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	for _, f := range d.Meta {
+		fmt.Fprintf(&b, "- **%s:** %s\n", f.Name, f.Value)
+	}
+
+	var last Role
+	for _, e := range d.Entries {
+		if e.Role != last {
+			fmt.Fprintf(&b, "\n## %s\n", roleTitle(e.Role))
+			last = e.Role
+		}
+		b.WriteString("\n")
+		switch {
+		case e.Tool != nil:
+			writeToolMarkdown(&b, e.Tool)
+		case e.Role == RoleOutput:
+			b.WriteString(fenced("", e.Text))
+		case e.Role == RoleUser:
+			// Quoted, so prompts stand apart from the replies
+			for _, line := range strings.Split(e.Text, "\n") {
+				b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+		default:
+			b.WriteString(strings.TrimSpace(e.Text) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeToolMarkdown(b *strings.Builder, call *ToolCall) {
+	summary := call.Name
+	if call.Summary != "" && call.Summary != call.Name {
+		summary += " " + call.Summary
+	}
+	if call.IsError {
+		summary += " (failed)"
+	}
+	fmt.Fprintf(b, "<details>\n<summary>%s</summary>\n\n", template.HTMLEscapeString(summary))
+	switch {
+	case call.Diff != "":
+		b.WriteString(fenced("diff", call.Diff))
+	case call.Name == "Bash":
+		b.WriteString(fenced("sh", call.Input))
+	case call.Input != "":
+		b.WriteString(fenced("json", call.Input))
+	}
+	if call.Output != "" {
+		b.WriteString("\n")
+		b.WriteString(fenced("", call.Output))
+	}
+	b.WriteString("\n</details>\n")
+}
+
+// fenced wraps text in a code fence longer than any backtick run inside it.
+func fenced(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence + "\n"
+}
+
+func roleTitle(r Role) string {
+	switch r {
+	case RoleUser:
+		return "User"
+	case RoleAssistant:
+		return "Claude"
+	default:
+		return "Terminal output"
+	}
+}
+
+// HTML writes d as a standalone HTML page with inline styles. Tool calls
+// are collapsible.
+func (d *Doc) HTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, d)
+}
+
+var htmlTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"roleTitle": roleTitle,
+	"diffLines": func(diff string) []string { return strings.Split(diff, "\n") },
+	"lineClass": func(line string) string {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			return "add"
+		case strings.HasPrefix(line, "-"):
+			return "del"
+		case line == "@@":
+			return "hunk"
+		}
+		return ""
+	},
+	"newRole": func(entries []Entry, i int) bool {
+		return i == 0 || entries[i-1].Role != entries[i].Role
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+  :root { color-scheme: light dark; --fg: #1f1f1f; --bg: #ffffff; --muted: #6b6b6b; --line: #e3e3e3; --code: #f6f6f4; --accent: #d77757; --add: #e6f4ea; --del: #fbe9eb; }
+  @media (prefers-color-scheme: dark) { :root { --fg: #ececec; --bg: #1b1b1b; --muted: #999; --line: #333; --code: #242424; --add: #1e3a26; --del: #3f1f24; } }
+  body { font: 15px/1.55 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; color: var(--fg); background: var(--bg); max-width: 860px; margin: 2rem auto; padding: 0 1rem; }
+  h1 { font-size: 1.5rem; margin-bottom: .25rem; }
+  .meta { color: var(--muted); font-size: .85rem; margin: 0 0 2rem; padding: 0; list-style: none; }
+  .meta li { display: inline; margin-right: 1rem; }
+  h2 { font-size: .8rem; text-transform: uppercase; letter-spacing: .06em; color: var(--muted); border-top: 1px solid var(--line); padding-top: 1rem; margin-top: 1.5rem; }
+  h2.assistant { color: var(--accent); }
+  .text { white-space: pre-wrap; margin: .5rem 0; }
+  .user .text { border-left: 3px solid var(--accent); padding-left: .75rem; }
+  pre { background: var(--code); padding: .75rem; overflow-x: auto; font: 12.5px/1.45 ui-monospace, SFMono-Regular, Menlo, monospace; border-radius: 6px; margin: .5rem 0; }
+  pre span { display: block; }
+  pre .add { background: var(--add); }
+  pre .del { background: var(--del); }
+  pre .hunk { color: var(--muted); }
+  details { margin: .4rem 0; }
+  summary { cursor: pointer; font: 13px ui-monospace, SFMono-Regular, Menlo, monospace; color: var(--muted); }
+  summary b { color: var(--fg); font-weight: 600; }
+  .failed summary { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul class="meta">{{range .Meta}}<li><strong>{{.Name}}</strong> {{.Value}}</li>{{end}}</ul>
+{{- $entries := .Entries}}
+{{- range $i, $e := .Entries}}
+{{- if newRole $entries $i}}
+<h2 class="{{$e.Role}}">{{roleTitle $e.Role}}</h2>
+{{- end}}
+{{- if $e.Tool}}{{with $e.Tool}}
+<details{{if .IsError}} class="failed"{{end}}><summary><b>{{.Name}}</b>{{if ne .Summary .Name}} {{.Summary}}{{end}}{{if .IsError}} (failed){{end}}</summary>
+{{- if .Diff}}
+<pre>{{range diffLines .Diff}}<span class="{{lineClass .}}">{{.}}</span>{{end}}</pre>
+{{- else if .Input}}
+<pre>{{.Input}}</pre>
+{{- end}}
+{{- if .Output}}
+<pre>{{.Output}}</pre>
+{{- end}}
+</details>
+{{- end}}
+{{- else if eq $e.Role "output"}}
+<pre>{{$e.Text}}</pre>
+{{- else}}
+<div class="{{$e.Role}}"><div class="text">{{$e.Text}}</div></div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
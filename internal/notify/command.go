@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/allenan/herd/internal/shellquote"
+)
+
+// commandTimeout bounds how long a notification command may run before it
+// is killed, so a hung command can't pile up processes.
+const commandTimeout = 30 * time.Second
+
+// commandNotifier runs a shell command for each event.
+type commandNotifier struct {
+	muter
+	command   string
+	switchCmd []string
+}
+
+// NewCommand creates a notifier that runs command with sh -c for each
+// event. The event is passed in HERD_EVENT, HERD_SESSION_ID, HERD_SESSION,
+// HERD_PROJECT, HERD_STATUS, HERD_CONTEXT_PERCENT, HERD_ESCALATED,
+// HERD_TITLE and HERD_MESSAGE. HERD_SWITCH_CMD is a shell command that
+// switches to the session, for notification tools with click actions.
+func NewCommand(command string, switchCmd []string) Notifier {
+	return &commandNotifier{command: command, switchCmd: switchCmd}
+}
+
+func (n *commandNotifier) Notify(event Event) {
+	if n.IsMuted() {
+		return
+	}
+	title, detail, ok := describe(event)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.command)
+	cmd.Env = append(os.Environ(),
+		"HERD_EVENT="+event.Kind.String(),
+		"HERD_SESSION_ID="+event.SessionID,
+		"HERD_SESSION="+event.SessionName,
+		"HERD_PROJECT="+event.ProjectName,
+		"HERD_STATUS="+string(event.Status),
+		fmt.Sprintf("HERD_CONTEXT_PERCENT=%d", event.ContextPercent),
+		fmt.Sprintf("HERD_ESCALATED=%t", event.Escalated),
+		"HERD_TITLE="+title,
+		"HERD_MESSAGE="+detail,
+	)
+	if len(n.switchCmd) > 0 && event.SessionID != "" {
+		cmd.Env = append(cmd.Env, "HERD_SWITCH_CMD="+shellquote.Join(append(slices.Clip(n.switchCmd), event.SessionID)...))
+	}
+	go func() {
+		defer cancel()
+		cmd.Run()
+	}()
+}
@@ -2,10 +2,12 @@ package notify
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
-	"sync"
+	"slices"
 
 	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/shellquote"
 )
 
 const (
@@ -13,63 +15,48 @@ const (
 	soundDone  = "/System/Library/Sounds/Glass.aiff"
 )
 
+// darwinNotifier uses osascript for desktop notifications and afplay for
+// system sounds. When terminal-notifier is installed it is used instead of
+// osascript, so clicking a notification switches to its session.
 type darwinNotifier struct {
-	mu    sync.Mutex
-	muted bool
-}
-
-// New creates a macOS notifier that uses osascript for desktop
-// notifications and afplay for system sounds.
-func New() Notifier {
-	return &darwinNotifier{}
+	muter
+	switchCmd []string
 }
 
 func (n *darwinNotifier) Notify(event Event) {
-	n.mu.Lock()
-	muted := n.muted
-	n.mu.Unlock()
-	if muted {
+	if n.IsMuted() {
 		return
 	}
 
-	var title, subtitle, body, sound string
-	switch event.Status {
-	case session.StatusInput:
-		title = "Herd: Needs Input"
-		subtitle = event.SessionName
-		body = event.ProjectName
-		sound = soundInput
-	case session.StatusPlanReady:
-		title = "Herd: Plan Ready"
-		subtitle = event.SessionName
-		body = event.ProjectName
-		sound = soundInput
-	case session.StatusDone:
-		title = "Herd: Task Complete"
-		subtitle = event.SessionName
-		body = event.ProjectName
-		sound = soundDone
-	default:
+	title, body, ok := describe(event)
+	if !ok {
 		return
 	}
+	subtitle := event.SessionName
+	sound := soundInput
+	if event.Kind == KindStatus && event.Status == session.StatusDone {
+		sound = soundDone
+	}
 
 	go func() {
-		script := fmt.Sprintf(`display notification %q with title %q subtitle %q`, body, title, subtitle)
-		exec.Command("osascript", "-e", script).Run()
+		if tn, err := exec.LookPath("terminal-notifier"); err == nil && len(n.switchCmd) > 0 && event.SessionID != "" {
+			args := []string{
+				"-title", title, "-subtitle", subtitle, "-message", body,
+				"-group", "herd-" + event.SessionID, // replaces older alerts for the session
+				"-execute", shellquote.Join(append(slices.Clip(n.switchCmd), event.SessionID)...),
+			}
+			// Set by Terminal, iTerm2 etc. for their children; the tmux
+			// server inherits it from the terminal herd was started in
+			if app := os.Getenv("__CFBundleIdentifier"); app != "" {
+				args = append(args, "-activate", app)
+			}
+			exec.Command(tn, args...).Run()
+		} else {
+			script := fmt.Sprintf(`display notification %q with title %q subtitle %q`, body, title, subtitle)
+			exec.Command("osascript", "-e", script).Run()
+		}
 		if sound != "" {
 			exec.Command("afplay", sound).Run()
 		}
 	}()
 }
-
-func (n *darwinNotifier) SetMuted(muted bool) {
-	n.mu.Lock()
-	n.muted = muted
-	n.mu.Unlock()
-}
-
-func (n *darwinNotifier) IsMuted() bool {
-	n.mu.Lock()
-	defer n.mu.Unlock()
-	return n.muted
-}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// sendTimeout bounds a plain notify-send call.
+	sendTimeout = 10 * time.Second
+	// clickTimeout is how long a notification with a click action is
+	// waited on; after it the click no longer switches sessions.
+	clickTimeout = 10 * time.Minute
+)
+
+// freedesktopNotifier shows notifications through notify-send. With
+// libnotify 0.7.10 or later the notification gets a default action, and
+// clicking it switches to the session and raises the terminal.
+type freedesktopNotifier struct {
+	muter
+	send      string // path to notify-send
+	switchCmd []string
+}
+
+func (n *freedesktopNotifier) Notify(event Event) {
+	if n.IsMuted() {
+		return
+	}
+	title, body, ok := describe(event)
+	if !ok {
+		return
+	}
+	message := event.SessionName
+	if body != "" {
+		message += " — " + body
+	}
+
+	go func() {
+		args := []string{"--app-name=herd", title, message}
+		if len(n.switchCmd) == 0 || event.SessionID == "" {
+			n.run(sendTimeout, args...)
+			return
+		}
+		// --wait blocks until the notification is closed and prints the
+		// chosen action
+		ctx, cancel := context.WithTimeout(context.Background(), clickTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, n.send, append([]string{"--action=default=Switch", "--wait"}, args...)...).Output()
+		if err != nil {
+			if ctx.Err() == nil {
+				// Older notify-send without --action
+				n.run(sendTimeout, args...)
+			}
+			return
+		}
+		if strings.TrimSpace(string(out)) == "default" {
+			cmd := append(slices.Clip(n.switchCmd), event.SessionID)
+			exec.Command(cmd[0], cmd[1:]...).Run()
+			focusTerminal()
+		}
+	}()
+}
+
+func (n *freedesktopNotifier) run(timeout time.Duration, args ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	exec.CommandContext(ctx, n.send, args...).Run()
+}
+
+// focusTerminal raises the X11 window of the terminal herd was started in.
+// Most terminals export WINDOWID to their children, and the tmux server
+// inherits it; xdotool or wmctrl does the raising. Without them, or on
+// Wayland, the session is switched but the window stays where it is.
+func focusTerminal() {
+	id := os.Getenv("WINDOWID")
+	if id == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if tool, err := exec.LookPath("xdotool"); err == nil {
+		exec.CommandContext(ctx, tool, "windowactivate", id).Run()
+	} else if tool, err := exec.LookPath("wmctrl"); err == nil {
+		exec.CommandContext(ctx, tool, "-i", "-a", id).Run()
+	}
+}
@@ -1,12 +1,38 @@
 package notify
 
-import "github.com/allenan/herd/internal/session"
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// EventKind says what an Event reports.
+type EventKind int
+
+const (
+	KindStatus  EventKind = iota // Status is the session's new status
+	KindContext                  // context usage crossed the warning threshold
+)
+
+func (k EventKind) String() string {
+	if k == KindContext {
+		return "context"
+	}
+	return "status"
+}
 
 // Event describes a session status transition worth notifying about.
 type Event struct {
-	SessionName string
-	ProjectName string
-	Status      session.Status
+	Kind           EventKind
+	SessionID      string
+	SessionName    string
+	ProjectName    string
+	Status         session.Status
+	ContextPercent int  // set for KindContext
+	Escalated      bool // repeated because the session is still waiting
 }
 
 // Notifier sends desktop notifications and plays sounds.
@@ -15,3 +41,45 @@ type Notifier interface {
 	SetMuted(muted bool)
 	IsMuted() bool
 }
+
+// New creates the desktop notifier for this platform: freedesktop
+// notifications when notify-send is installed outside macOS, otherwise
+// Notification Center. switchCmd is the command, without the session ID,
+// that a click on a notification runs to switch to its session
+// (`herd switch`); nil disables click-through.
+func New(switchCmd []string) Notifier {
+	if runtime.GOOS != "darwin" {
+		if send, err := exec.LookPath("notify-send"); err == nil {
+			return &freedesktopNotifier{send: send, switchCmd: switchCmd}
+		}
+	}
+	return &darwinNotifier{switchCmd: switchCmd}
+}
+
+// SwitchURL returns the herd:// URL that `herd switch` accepts for a
+// session, for notifications that can only open URLs.
+func SwitchURL(profile, sessionID string) string {
+	u := "herd://switch/" + url.PathEscape(sessionID)
+	if profile != "" {
+		u += "?profile=" + url.QueryEscape(profile)
+	}
+	return u
+}
+
+// describe returns the headline and detail line shown for e, or false for
+// events no notifier reports.
+func describe(e Event) (title, detail string, ok bool) {
+	switch {
+	case e.Kind == KindContext:
+		return fmt.Sprintf("Herd: Context %d%% Full", e.ContextPercent), "Consider /compact or a fresh session", true
+	case e.Status == session.StatusInput && e.Escalated:
+		return "Herd: Still Needs Input", e.ProjectName, true
+	case e.Status == session.StatusInput:
+		return "Herd: Needs Input", e.ProjectName, true
+	case e.Status == session.StatusPlanReady:
+		return "Herd: Plan Ready", e.ProjectName, true
+	case e.Status == session.StatusDone:
+		return "Herd: Task Complete", e.ProjectName, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// Rules decide which events are worth a notification. They are stored in
+// the profile config under "notifications".
+type Rules struct {
+	// Statuses limits status notifications to these statuses (e.g. only
+	// "input"). Empty means all of input, plan_ready and done.
+	Statuses []session.Status `json:"statuses,omitempty"`
+
+	MutedSessions []string `json:"muted_sessions,omitempty"` // session IDs
+	MutedProjects []string `json:"muted_projects,omitempty"` // project names
+
+	// QuietHours suppresses everything between Start and End ("22:00",
+	// "07:30"); the range may wrap past midnight.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+
+	// SkipVisible drops events for a session shown in the viewport while a
+	// herd client has focus.
+	SkipVisible bool `json:"skip_visible,omitempty"`
+
+	// EscalateAfter repeats the "needs input" notification every this many
+	// minutes while a session keeps waiting. 0 disables.
+	EscalateAfter int `json:"escalate_after_minutes,omitempty"`
+}
+
+// QuietHours is a daily time range in local time, formatted as HH:MM.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Off   bool   `json:"off,omitempty"` // turned off from the sidebar, range kept
+}
+
+// Rule names a rule that can be switched on and off from the sidebar.
+type Rule string
+
+const (
+	RuleInputOnly   Rule = "input_only"   // notify only when a session needs input
+	RuleQuietHours  Rule = "quiet_hours"  // honor the configured quiet hours
+	RuleSkipVisible Rule = "skip_visible" // skip the session shown in the viewport
+	RuleEscalate    Rule = "escalate"     // repeat "needs input" for waiting sessions
+)
+
+// defaultEscalateAfter is the escalation interval, in minutes, used when
+// escalation is turned on without one configured.
+const defaultEscalateAfter = 10
+
+// Validate reports malformed rules.
+func (r Rules) Validate() error {
+	for _, st := range r.Statuses {
+		switch st {
+		case session.StatusInput, session.StatusPlanReady, session.StatusDone:
+		default:
+			return fmt.Errorf("notifications: unsupported status %q (want input, plan_ready or done)", st)
+		}
+	}
+	if q := r.QuietHours; q != nil {
+		if _, err := time.Parse("15:04", q.Start); err != nil {
+			return fmt.Errorf("notifications: invalid quiet_hours start %q: want HH:MM", q.Start)
+		}
+		if _, err := time.Parse("15:04", q.End); err != nil {
+			return fmt.Errorf("notifications: invalid quiet_hours end %q: want HH:MM", q.End)
+		}
+	}
+	if r.EscalateAfter < 0 {
+		return fmt.Errorf("notifications: escalate_after_minutes must not be negative")
+	}
+	return nil
+}
+
+// Allow reports whether e passes the mute, status and quiet-hour rules at
+// time now. Visibility is checked by the caller, which knows the layout.
+func (r Rules) Allow(e Event, now time.Time) bool {
+	if r.SessionMuted(e.SessionID) || r.ProjectMuted(e.ProjectName) {
+		return false
+	}
+	if e.Kind == KindStatus && len(r.Statuses) > 0 && !slices.Contains(r.Statuses, e.Status) {
+		return false
+	}
+	return !r.quiet(now)
+}
+
+func (r Rules) quiet(now time.Time) bool {
+	if r.QuietHours == nil || r.QuietHours.Off {
+		return false
+	}
+	start, err1 := time.Parse("15:04", r.QuietHours.Start)
+	end, err2 := time.Parse("15:04", r.QuietHours.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	mins := now.Hour()*60 + now.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return mins >= from && mins < to
+	}
+	return mins >= from || mins < to // wraps past midnight
+}
+
+// SessionMuted reports whether the session is muted.
+func (r Rules) SessionMuted(id string) bool {
+	return id != "" && slices.Contains(r.MutedSessions, id)
+}
+
+// ProjectMuted reports whether the whole project is muted.
+func (r Rules) ProjectMuted(project string) bool {
+	return project != "" && slices.Contains(r.MutedProjects, project)
+}
+
+// ToggleSession mutes or unmutes a session and returns its new state.
+func (r *Rules) ToggleSession(id string) bool {
+	return toggle(&r.MutedSessions, id)
+}
+
+// ToggleProject mutes or unmutes a project and returns its new state.
+func (r *Rules) ToggleProject(project string) bool {
+	return toggle(&r.MutedProjects, project)
+}
+
+func toggle(list *[]string, v string) bool {
+	if i := slices.Index(*list, v); i >= 0 {
+		*list = slices.Delete(*list, i, i+1)
+		return false
+	}
+	*list = append(*list, v)
+	return true
+}
+
+// Enabled reports whether rule is on.
+func (r Rules) Enabled(rule Rule) bool {
+	switch rule {
+	case RuleInputOnly:
+		return slices.Equal(r.Statuses, []session.Status{session.StatusInput})
+	case RuleQuietHours:
+		return r.QuietHours != nil && !r.QuietHours.Off
+	case RuleSkipVisible:
+		return r.SkipVisible
+	case RuleEscalate:
+		return r.EscalateAfter > 0
+	}
+	return false
+}
+
+// Toggle switches rule on or off and returns its new state. Quiet hours
+// can only be toggled once a range is configured.
+func (r *Rules) Toggle(rule Rule) (bool, error) {
+	on := !r.Enabled(rule)
+	switch rule {
+	case RuleInputOnly:
+		r.Statuses = nil
+		if on {
+			r.Statuses = []session.Status{session.StatusInput}
+		}
+	case RuleQuietHours:
+		if r.QuietHours == nil {
+			return false, fmt.Errorf("set quiet_hours in the profile config first")
+		}
+		r.QuietHours.Off = !on
+	case RuleSkipVisible:
+		r.SkipVisible = on
+	case RuleEscalate:
+		r.EscalateAfter = 0
+		if on {
+			r.EscalateAfter = defaultEscalateAfter
+		}
+	default:
+		return false, fmt.Errorf("unknown notification rule %q", rule)
+	}
+	return on, nil
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// clock is a time of day on an arbitrary date.
+func clock(hour, minute int) time.Time {
+	return time.Date(2026, 3, 1, hour, minute, 0, 0, time.Local)
+}
+
+func TestQuiet(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours *QuietHours
+		now   time.Time
+		want  bool
+	}{
+		{"none configured", nil, clock(23, 0), false},
+		{"same day, inside", &QuietHours{Start: "12:00", End: "13:30"}, clock(12, 45), true},
+		{"same day, at start", &QuietHours{Start: "12:00", End: "13:30"}, clock(12, 0), true},
+		{"same day, at end", &QuietHours{Start: "12:00", End: "13:30"}, clock(13, 30), false},
+		{"same day, before", &QuietHours{Start: "12:00", End: "13:30"}, clock(11, 59), false},
+		{"wrapping, late evening", &QuietHours{Start: "22:00", End: "07:30"}, clock(23, 15), true},
+		{"wrapping, early morning", &QuietHours{Start: "22:00", End: "07:30"}, clock(3, 0), true},
+		{"wrapping, at end", &QuietHours{Start: "22:00", End: "07:30"}, clock(7, 30), false},
+		{"wrapping, daytime", &QuietHours{Start: "22:00", End: "07:30"}, clock(15, 0), false},
+		{"turned off", &QuietHours{Start: "22:00", End: "07:30", Off: true}, clock(23, 15), false},
+		{"malformed", &QuietHours{Start: "late", End: "07:30"}, clock(23, 15), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Rules{QuietHours: tt.hours}
+			if got := r.quiet(tt.now); got != tt.want {
+				t.Errorf("quiet(%s) = %v, want %v", tt.now.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	input := Event{Kind: KindStatus, SessionID: "s1", ProjectName: "web", Status: session.StatusInput}
+	done := Event{Kind: KindStatus, SessionID: "s1", ProjectName: "web", Status: session.StatusDone}
+	context := Event{Kind: KindContext, SessionID: "s1", ProjectName: "web", ContextPercent: 85}
+	inputOnly := []session.Status{session.StatusInput}
+	night := &QuietHours{Start: "22:00", End: "07:00"}
+
+	tests := []struct {
+		name  string
+		rules Rules
+		event Event
+		now   time.Time
+		want  bool
+	}{
+		{"no rules", Rules{}, done, clock(12, 0), true},
+		{"status allowed", Rules{Statuses: inputOnly}, input, clock(12, 0), true},
+		{"status filtered", Rules{Statuses: inputOnly}, done, clock(12, 0), false},
+		{"context warnings ignore statuses", Rules{Statuses: inputOnly}, context, clock(12, 0), true},
+		{"session muted", Rules{MutedSessions: []string{"s1"}}, input, clock(12, 0), false},
+		{"other session muted", Rules{MutedSessions: []string{"s2"}}, input, clock(12, 0), true},
+		{"project muted", Rules{MutedProjects: []string{"web"}}, context, clock(12, 0), false},
+		{"quiet hours", Rules{QuietHours: night}, input, clock(23, 0), false},
+		{"outside quiet hours", Rules{QuietHours: night}, input, clock(12, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Allow(tt.event, tt.now); got != tt.want {
+				t.Errorf("Allow(%+v) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggle(t *testing.T) {
+	r := Rules{QuietHours: &QuietHours{Start: "22:00", End: "07:00"}}
+	steps := []struct {
+		rule Rule
+		want bool
+	}{
+		{RuleInputOnly, true},
+		{RuleInputOnly, false},
+		{RuleQuietHours, false},
+		{RuleQuietHours, true},
+		{RuleSkipVisible, true},
+		{RuleEscalate, true},
+		{RuleEscalate, false},
+	}
+	for i, st := range steps {
+		on, err := r.Toggle(st.rule)
+		if err != nil {
+			t.Fatalf("step %d: Toggle(%s): %v", i, st.rule, err)
+		}
+		if on != st.want || r.Enabled(st.rule) != st.want {
+			t.Errorf("step %d: Toggle(%s) = %v, Enabled = %v, want %v", i, st.rule, on, r.Enabled(st.rule), st.want)
+		}
+	}
+	if r.Statuses != nil || r.QuietHours.Off || !r.SkipVisible || r.EscalateAfter != 0 {
+		t.Errorf("rules after toggling = %+v", r)
+	}
+
+	r.Toggle(RuleEscalate)
+	if r.EscalateAfter != defaultEscalateAfter {
+		t.Errorf("EscalateAfter = %d, want %d", r.EscalateAfter, defaultEscalateAfter)
+	}
+	if _, err := (&Rules{}).Toggle(RuleQuietHours); err == nil {
+		t.Error("Toggle(quiet_hours) without a range = nil error, want an error")
+	}
+	if _, err := r.Toggle("bogus"); err == nil {
+		t.Error("Toggle(bogus) = nil error, want an error")
+	}
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// SinkConfig configures one notification destination in the profile
+// config. Without any sinks herd uses desktop notifications.
+type SinkConfig struct {
+	Type     string           `json:"type"`               // "desktop", "webhook", "command" or "terminal"
+	URL      string           `json:"url,omitempty"`      // webhook: where events are POSTed
+	Format   string           `json:"format,omitempty"`   // webhook: "json" (default), "slack" or "ntfy"; terminal: "osc9" (default), "osc777" or "bell"
+	Command  string           `json:"command,omitempty"`  // command: run with sh -c, event in HERD_* env vars
+	Statuses []session.Status `json:"statuses,omitempty"` // status events this sink gets, empty = all
+}
+
+// Validate reports a sink that cannot be built.
+func (c SinkConfig) Validate() error {
+	switch c.Type {
+	case "desktop":
+	case "webhook":
+		if c.URL == "" {
+			return fmt.Errorf("webhook sink needs a url")
+		}
+		switch c.Format {
+		case "", "json", "slack", "ntfy":
+		default:
+			return fmt.Errorf("webhook sink: unknown format %q (want json, slack or ntfy)", c.Format)
+		}
+	case "command":
+		if c.Command == "" {
+			return fmt.Errorf("command sink needs a command")
+		}
+	case "terminal":
+		switch c.Format {
+		case "", "osc9", "osc777", "bell":
+		default:
+			return fmt.Errorf("terminal sink: unknown format %q (want osc9, osc777 or bell)", c.Format)
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q (want desktop, webhook, command or terminal)", c.Type)
+	}
+	return nil
+}
+
+// FromConfig builds the notifier for a profile: desktop notifications when
+// no sinks are configured, otherwise a fan-out to every sink. switchCmd is
+// passed on to sinks that support click-through (see New), and profile to
+// those that link to sessions by URL.
+func FromConfig(sinks []SinkConfig, switchCmd []string, profile string) (Notifier, error) {
+	if len(sinks) == 0 {
+		return New(switchCmd), nil
+	}
+	fan := &fanout{}
+	for i, c := range sinks {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("sink %d: %w", i+1, err)
+		}
+		var n Notifier
+		switch c.Type {
+		case "desktop":
+			n = New(switchCmd)
+		case "webhook":
+			n = NewWebhook(c.URL, c.Format)
+		case "command":
+			n = NewCommand(c.Command, switchCmd)
+		case "terminal":
+			n = NewTerminal(c.Format, profile)
+		}
+		fan.sinks = append(fan.sinks, filtered{Notifier: n, statuses: c.Statuses})
+	}
+	return fan, nil
+}
+
+// filtered is a sink that only gets some status events. Context warnings
+// always pass.
+type filtered struct {
+	Notifier
+	statuses []session.Status
+}
+
+func (f filtered) wants(e Event) bool {
+	return e.Kind != KindStatus || len(f.statuses) == 0 || slices.Contains(f.statuses, e.Status)
+}
+
+// fanout sends each event to every sink that wants it. Muting it mutes
+// all sinks.
+type fanout struct {
+	muter
+	sinks []filtered
+}
+
+func (f *fanout) Notify(event Event) {
+	if f.IsMuted() {
+		return
+	}
+	for _, s := range f.sinks {
+		if s.wants(event) {
+			s.Notify(event)
+		}
+	}
+}
+
+// muter implements the muting half of Notifier.
+type muter struct {
+	mu    sync.Mutex
+	muted bool
+}
+
+func (m *muter) SetMuted(muted bool) {
+	m.mu.Lock()
+	m.muted = muted
+	m.mu.Unlock()
+}
+
+func (m *muter) IsMuted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// recorder is a sink that keeps the events it gets.
+type recorder struct {
+	muter
+	events []Event
+}
+
+func (r *recorder) Notify(e Event) { r.events = append(r.events, e) }
+
+func TestFanoutFiltersByStatus(t *testing.T) {
+	all := &recorder{}
+	inputOnly := &recorder{}
+	doneOnly := &recorder{}
+	fan := &fanout{sinks: []filtered{
+		{Notifier: all},
+		{Notifier: inputOnly, statuses: []session.Status{session.StatusInput}},
+		{Notifier: doneOnly, statuses: []session.Status{session.StatusDone}},
+	}}
+
+	events := []Event{
+		{Kind: KindStatus, Status: session.StatusInput},
+		{Kind: KindStatus, Status: session.StatusDone},
+		{Kind: KindStatus, Status: session.StatusPlanReady},
+		{Kind: KindContext, ContextPercent: 90},
+	}
+	for _, e := range events {
+		fan.Notify(e)
+	}
+
+	kinds := func(r *recorder) []string {
+		var out []string
+		for _, e := range r.events {
+			if e.Kind == KindContext {
+				out = append(out, "context")
+			} else {
+				out = append(out, string(e.Status))
+			}
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		sink *recorder
+		want []string
+	}{
+		{"unfiltered", all, []string{"input", "done", "plan_ready", "context"}},
+		{"input only", inputOnly, []string{"input", "context"}},
+		{"done only", doneOnly, []string{"done", "context"}},
+	}
+	for _, tt := range tests {
+		if got := kinds(tt.sink); !slices.Equal(got, tt.want) {
+			t.Errorf("%s sink got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFanoutMuted(t *testing.T) {
+	sink := &recorder{}
+	fan := &fanout{sinks: []filtered{{Notifier: sink}}}
+	fan.SetMuted(true)
+	fan.Notify(Event{Kind: KindStatus, Status: session.StatusInput})
+	if len(sink.events) != 0 {
+		t.Errorf("muted fanout delivered %d events", len(sink.events))
+	}
+}
+
+func TestFromConfigRejectsInvalidSink(t *testing.T) {
+	_, err := FromConfig([]SinkConfig{
+		{Type: "terminal"},
+		{Type: "webhook", Format: "slack"},
+	}, nil, "")
+	if err == nil || err.Error() != "sink 2: webhook sink needs a url" {
+		t.Errorf("err = %v, want sink 2 missing url", err)
+	}
+}
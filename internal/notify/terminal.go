@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"os"
+	"strings"
+)
+
+// terminalNotifier asks the terminal emulator to raise the notification,
+// which also works over SSH. The sidebar runs in a tmux pane, so sequences
+// are written to its tty wrapped for tmux passthrough (allow-passthrough is
+// on) and reach the attached client's terminal.
+type terminalNotifier struct {
+	muter
+	format  string
+	profile string
+}
+
+// NewTerminal creates a notifier that writes an escape sequence to the
+// controlling terminal. format is "osc9" (iTerm2, WezTerm, Kitty, Ghostty),
+// "osc777" (foot, urxvt, WezTerm) or "bell". OSC 777 bodies end with the
+// session's herd:// URL (see SwitchURL) for profile, so a notification
+// daemon that opens URLs on click switches to the session.
+func NewTerminal(format, profile string) Notifier {
+	return &terminalNotifier{format: format, profile: profile}
+}
+
+func (n *terminalNotifier) Notify(event Event) {
+	if n.IsMuted() {
+		return
+	}
+	title, detail, ok := describe(event)
+	if !ok {
+		return
+	}
+	message := event.SessionName
+	if detail != "" {
+		message += " — " + detail
+	}
+
+	var seq string
+	switch n.format {
+	case "bell":
+		// A bell is handled by tmux itself (bell-action), not passed through
+		writeTTY("\a")
+		return
+	case "osc777":
+		if event.SessionID != "" {
+			message += " " + SwitchURL(n.profile, event.SessionID)
+		}
+		seq = "\x1b]777;notify;" + oscText(title, true) + ";" + oscText(message, false) + "\a"
+	default:
+		seq = "\x1b]9;" + oscText(title+": "+message, false) + "\a"
+	}
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	writeTTY(seq)
+}
+
+// oscText strips control characters that would end the sequence early.
+// OSC 777 fields are separated by semicolons, so the title can't have any.
+func oscText(s string, noSemicolon bool) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (noSemicolon && r == ';') {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func writeTTY(s string) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	defer tty.Close()
+	tty.WriteString(s)
+}
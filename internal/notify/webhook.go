@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// webhookNotifier POSTs events to an HTTP endpoint.
+type webhookNotifier struct {
+	muter
+	url    string
+	format string
+	client *http.Client
+}
+
+// NewWebhook creates a notifier that POSTs each event to url. format picks
+// the payload: "slack" sends {"text": ...} for incoming webhooks, "ntfy"
+// sends the message as the body with a Title header, and anything else
+// sends the event as JSON.
+func NewWebhook(url, format string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		format: format,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// webhookEvent is the JSON payload of the default format.
+type webhookEvent struct {
+	Kind           string `json:"kind"`
+	SessionID      string `json:"session_id"`
+	Session        string `json:"session"`
+	Project        string `json:"project"`
+	Status         string `json:"status"`
+	ContextPercent int    `json:"context_percent,omitempty"`
+	Escalated      bool   `json:"escalated,omitempty"`
+	Title          string `json:"title"`
+	Message        string `json:"message"`
+}
+
+func (n *webhookNotifier) Notify(event Event) {
+	if n.IsMuted() {
+		return
+	}
+	req, err := n.request(event)
+	if err != nil || req == nil {
+		return
+	}
+	go func() {
+		resp, err := n.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+}
+
+// request builds the POST for event in the configured format, or nil if
+// the event isn't reported.
+func (n *webhookNotifier) request(event Event) (*http.Request, error) {
+	title, detail, ok := describe(event)
+	if !ok {
+		return nil, nil
+	}
+	message := event.SessionName
+	if detail != "" {
+		message += " — " + detail
+	}
+
+	var body []byte
+	contentType := "application/json"
+	switch n.format {
+	case "slack":
+		body, _ = json.Marshal(map[string]string{"text": "*" + title + "*\n" + message})
+	case "ntfy":
+		body = []byte(message)
+		contentType = "text/plain"
+	default:
+		body, _ = json.Marshal(webhookEvent{
+			Kind:           event.Kind.String(),
+			SessionID:      event.SessionID,
+			Session:        event.SessionName,
+			Project:        event.ProjectName,
+			Status:         string(event.Status),
+			ContextPercent: event.ContextPercent,
+			Escalated:      event.Escalated,
+			Title:          title,
+			Message:        message,
+		})
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if n.format == "ntfy" {
+		req.Header.Set("Title", strings.TrimPrefix(title, "Herd: "))
+		req.Header.Set("Tags", "herd")
+		if event.Kind == KindContext || event.Status == session.StatusInput {
+			req.Header.Set("Priority", "high")
+		}
+	}
+	return req, nil
+}
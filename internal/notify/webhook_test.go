@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// captured is a request received by the test server.
+type captured struct {
+	header http.Header
+	body   string
+}
+
+// serve starts a server that hands every request it gets to the returned
+// channel.
+func serve(t *testing.T) (*httptest.Server, <-chan captured) {
+	t.Helper()
+	ch := make(chan captured, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		body, _ := io.ReadAll(r.Body)
+		ch <- captured{header: r.Header.Clone(), body: string(body)}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func receive(t *testing.T, ch <-chan captured) captured {
+	t.Helper()
+	select {
+	case c := <-ch:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+		return captured{}
+	}
+}
+
+var inputEvent = Event{
+	Kind:        KindStatus,
+	SessionID:   "abc123",
+	SessionName: "fix-login",
+	ProjectName: "api",
+	Status:      session.StatusInput,
+}
+
+func TestWebhookJSON(t *testing.T) {
+	srv, ch := serve(t)
+	NewWebhook(srv.URL, "").Notify(inputEvent)
+	c := receive(t, ch)
+
+	if got := c.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var got webhookEvent
+	if err := json.Unmarshal([]byte(c.body), &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, c.body)
+	}
+	want := webhookEvent{
+		Kind:      "status",
+		SessionID: "abc123",
+		Session:   "fix-login",
+		Project:   "api",
+		Status:    "input",
+		Title:     "Herd: Needs Input",
+		Message:   "fix-login — api",
+	}
+	if got != want {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestWebhookSlack(t *testing.T) {
+	srv, ch := serve(t)
+	NewWebhook(srv.URL, "slack").Notify(Event{
+		Kind:           KindContext,
+		SessionName:    "fix-login",
+		ContextPercent: 85,
+	})
+	c := receive(t, ch)
+
+	if got := c.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var got map[string]string
+	if err := json.Unmarshal([]byte(c.body), &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, c.body)
+	}
+	want := "*Herd: Context 85% Full*\nfix-login — Consider /compact or a fresh session"
+	if len(got) != 1 || got["text"] != want {
+		t.Errorf("payload = %q, want only text %q", got, want)
+	}
+}
+
+func TestWebhookNtfy(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		title    string
+		body     string
+		priority string
+	}{
+		{"input is high priority", inputEvent, "Needs Input", "fix-login — api", "high"},
+		{
+			"done is default priority",
+			Event{SessionName: "fix-login", ProjectName: "api", Status: session.StatusDone},
+			"Task Complete", "fix-login — api", "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, ch := serve(t)
+			NewWebhook(srv.URL, "ntfy").Notify(tt.event)
+			c := receive(t, ch)
+
+			if c.body != tt.body {
+				t.Errorf("body = %q, want %q", c.body, tt.body)
+			}
+			for header, want := range map[string]string{
+				"Content-Type": "text/plain",
+				"Title":        tt.title,
+				"Tags":         "herd",
+				"Priority":     tt.priority,
+			} {
+				if got := c.header.Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookSkipsUnreportedStatus(t *testing.T) {
+	srv, ch := serve(t)
+	NewWebhook(srv.URL, "").Notify(Event{SessionName: "fix-login", Status: session.StatusRunning})
+	select {
+	case c := <-ch:
+		t.Errorf("unexpected webhook call: %s", c.body)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebhookMuted(t *testing.T) {
+	srv, ch := serve(t)
+	n := NewWebhook(srv.URL, "")
+	n.SetMuted(true)
+	n.Notify(inputEvent)
+	select {
+	case c := <-ch:
+		t.Errorf("muted webhook was called: %s", c.body)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,222 @@
+// Package outlog records the output of session panes to rotating files, so
+// it can be searched after tmux's scrollback has rolled over.
+package outlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxSize is the size a log grows to before it is rotated.
+	MaxSize = 5 << 20
+	// Keep is how many rotated logs are kept next to the current one.
+	Keep = 3
+)
+
+// Path returns the current log file of a session.
+func Path(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".log")
+}
+
+// Files returns a session's existing log files, oldest first.
+func Files(dir, sessionID string) []string {
+	var files []string
+	for i := Keep; i >= 0; i-- {
+		path := rotated(Path(dir, sessionID), i)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// Remove deletes all of a session's logs.
+func Remove(dir, sessionID string) {
+	for _, path := range Files(dir, sessionID) {
+		os.Remove(path)
+	}
+}
+
+// rotated returns the name of the nth rotated log, path itself for n = 0.
+func rotated(path string, n int) string {
+	if n == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// Writer appends pane output to a log file with terminal escape sequences
+// and carriage returns removed, rotating the file when it passes MaxSize.
+type Writer struct {
+	path  string
+	file  *os.File
+	size  int64
+	strip stripper
+}
+
+// Open opens the log at path for appending, creating its directory.
+func Open(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	w := &Writer{path: path}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open output log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open output log: %w", err)
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+// Write logs the printable part of p. It always reports len(p) written
+// unless the file can't be written.
+func (w *Writer) Write(p []byte) (int, error) {
+	text := w.strip.strip(p)
+	if len(text) == 0 {
+		return len(p), nil
+	}
+	if w.size+int64(len(text)) > MaxSize && w.size > 0 {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(text)
+	w.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rotate shifts log → log.1 → log.2 …, dropping the oldest, and starts a
+// new log.
+func (w *Writer) rotate() error {
+	w.file.Close()
+	os.Remove(rotated(w.path, Keep))
+	for i := Keep - 1; i >= 0; i-- {
+		os.Rename(rotated(w.path, i), rotated(w.path, i+1))
+	}
+	return w.open()
+}
+
+// Close closes the current log file.
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
+
+// stripper removes escape sequences from a byte stream. It keeps its
+// state between calls since sequences can be split across reads.
+type stripper struct {
+	state int
+}
+
+const (
+	stText = iota
+	stEsc  // after ESC
+	stCSI  // ESC [ … final byte
+	stOSC  // ESC ] … BEL or ST; also DCS, APC, PM
+	stOSCEsc
+)
+
+func (s *stripper) strip(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch s.state {
+		case stText:
+			switch {
+			case b == 0x1b:
+				s.state = stEsc
+			case b == '\n' || b == '\t' || b >= 0x20 && b != 0x7f:
+				out = append(out, b)
+			}
+		case stEsc:
+			switch b {
+			case '[':
+				s.state = stCSI
+			case ']', 'P', '_', '^':
+				s.state = stOSC
+			default:
+				s.state = stText // two-byte sequence, e.g. ESC =
+			}
+		case stCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stText
+			}
+		case stOSC:
+			switch b {
+			case 0x07:
+				s.state = stText
+			case 0x1b:
+				s.state = stOSCEsc
+			}
+		case stOSCEsc:
+			if b == '\\' {
+				s.state = stText
+			} else {
+				s.state = stOSC
+			}
+		}
+	}
+	return out
+}
+
+// overlapAnchor is how many lines at the top of the scrollback must match
+// the log for Overlap to line them up.
+const overlapAnchor = 3
+
+// Overlap returns the index of the first log line that is still in the
+// scrollback, len(log) when none is. The log and the scrollback end with
+// the same output, so the scrollback's first lines are looked for where
+// the log's tail would start, then further back for output that never
+// reached the scrollback (full-screen programs). Lines are compared
+// without trailing spaces.
+func Overlap(log, scrollback []string) int {
+	if len(log) == 0 || len(scrollback) == 0 {
+		return len(log)
+	}
+	at := func(lines []string, i int, anchor []string) bool {
+		for j, a := range anchor {
+			if strings.TrimRight(lines[i+j], " \t") != strings.TrimRight(a, " \t") {
+				return false
+			}
+		}
+		return true
+	}
+
+	anchor := scrollback[:min(overlapAnchor, len(scrollback))]
+	start := max(len(log)-len(scrollback), 0)
+	for p := start; p+len(anchor) <= len(log); p++ {
+		if at(log, p, anchor) {
+			return p
+		}
+	}
+	for p := min(start-1, len(log)-len(anchor)); p >= 0; p-- {
+		if at(log, p, anchor) {
+			return p
+		}
+	}
+
+	// Logging started after the scrollback: the whole log may be in it
+	anchor = log[:min(overlapAnchor, len(log))]
+	for i := 0; i+len(anchor) <= len(scrollback); i++ {
+		if at(scrollback, i, anchor) {
+			return 0
+		}
+	}
+	return len(log)
+}
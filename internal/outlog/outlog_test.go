@@ -0,0 +1,180 @@
+package outlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripper(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"plain text", []string{"hello\n"}, "hello\n"},
+		{"SGR color", []string{"\x1b[1;32mok\x1b[0m done\n"}, "ok done\n"},
+		{"carriage return and controls", []string{"50%\r100%\a\x7f\n"}, "50%100%\n"},
+		{"tabs kept", []string{"a\tb\n"}, "a\tb\n"},
+		{"two-byte sequence", []string{"\x1b=keypad\x1b>\n"}, "keypad\n"},
+		{"OSC title ended by BEL", []string{"\x1b]0;my title\aprompt$ "}, "prompt$ "},
+		{"OSC ended by ST", []string{"\x1b]8;;https://x.y\x1b\\link\x1b]8;;\x1b\\\n"}, "link\n"},
+		{"DCS passthrough", []string{"\x1bPtmux;\x1b\x1b]9;hi\a\x1b\\after\n"}, "after\n"},
+		{"CSI split after ESC", []string{"red\x1b", "[31mtext\n"}, "redtext\n"},
+		{"CSI split in parameters", []string{"\x1b[38;5", ";196mhot\x1b[", "0m\n"}, "hot\n"},
+		{"OSC split before ST", []string{"\x1b]0;title\x1b", "\\rest\n"}, "rest\n"},
+		{"OSC with ESC not ending it", []string{"\x1b]0;a\x1bb\a", "text\n"}, "text\n"},
+		{"UTF-8 kept", []string{"héllo ✓\n"}, "héllo ✓\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s stripper
+			var got strings.Builder
+			for _, c := range tt.chunks {
+				got.Write(s.strip([]byte(c)))
+			}
+			if got.String() != tt.want {
+				t.Errorf("strip(%q) = %q, want %q", tt.chunks, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterStripsAcrossWrites(t *testing.T) {
+	path := Path(t.TempDir(), "s1")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{"\x1b[3", "2mgreen\x1b", "[0m\n"} {
+		if n, err := w.Write([]byte(c)); err != nil || n != len(c) {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", c, n, err, len(c))
+		}
+	}
+	w.Close()
+	if got := readFile(t, path); got != "green\n" {
+		t.Errorf("log = %q, want %q", got, "green\n")
+	}
+}
+
+func TestWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := Path(dir, "s1")
+
+	// A full current log and every rotated slot taken
+	for i := Keep; i >= 1; i-- {
+		writeFile(t, rotated(path, i), "old."+string(rune('0'+i)))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(MaxSize); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	w, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	if got := readFile(t, path); got != "new\n" {
+		t.Errorf("current log = %q, want %q", got, "new\n")
+	}
+	if info, err := os.Stat(rotated(path, 1)); err != nil || info.Size() != MaxSize {
+		t.Errorf("log.1 should be the full log, got %v, %v", info, err)
+	}
+	for i := 2; i <= Keep; i++ {
+		want := "old." + string(rune('0'+i-1))
+		if got := readFile(t, rotated(path, i)); got != want {
+			t.Errorf("log.%d = %q, want %q", i, got, want)
+		}
+	}
+	if _, err := os.Stat(rotated(path, Keep+1)); !os.IsNotExist(err) {
+		t.Errorf("log.%d should not exist, got %v", Keep+1, err)
+	}
+
+	files := Files(dir, "s1")
+	if len(files) != Keep+1 || files[0] != rotated(path, Keep) || files[Keep] != path {
+		t.Errorf("Files = %v, want log.%d … log", files, Keep)
+	}
+}
+
+func TestWriterDoesNotRotateBelowMaxSize(t *testing.T) {
+	path := Path(t.TempDir(), "s1")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("a\n"))
+	w.Close()
+	w, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("b\n"))
+	w.Close()
+
+	if got := readFile(t, path); got != "a\nb\n" {
+		t.Errorf("log = %q, want both writes appended", got)
+	}
+	if _, err := os.Stat(rotated(path, 1)); !os.IsNotExist(err) {
+		t.Errorf("log was rotated below MaxSize")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        []string
+		scrollback []string
+		want       int
+	}{
+		{"tail in scrollback", []string{"a", "b", "c", "d", "e", "f"}, []string{"d", "e", "f"}, 3},
+		{"repeated lines in the tail", []string{"x", "y", "x", "y", "x", "y"}, []string{"x", "y"}, 4},
+		{"repeated lines before the tail", []string{"a", "b", "c", "a", "b", "c"}, []string{"a", "b", "c", "$"}, 3},
+		{"log started after the scrollback", []string{"c", "d"}, []string{"a", "b", "c", "d"}, 0},
+		{"scrollback cleared", []string{"a", "b", "c"}, []string{"$"}, 3},
+		{
+			"full-screen output only in the log",
+			[]string{"a", "b", "c", "d", "T", "U", "V", "e"},
+			[]string{"b", "c", "d", "e"},
+			1,
+		},
+		{"trailing spaces ignored", []string{"a", "b", "c"}, []string{"b  ", "c"}, 1},
+		{"empty scrollback", []string{"a", "b"}, nil, 2},
+		{"empty log", nil, []string{"a"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Overlap(tt.log, tt.scrollback); got != tt.want {
+				t.Errorf("Overlap(%q, %q) = %d, want %d", tt.log, tt.scrollback, got, tt.want)
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
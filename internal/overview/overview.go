@@ -0,0 +1,84 @@
+// Package overview gathers the sessions of every profile whose tmux server
+// is running, for a combined view of what needs attention.
+package overview
+
+import (
+	"sort"
+
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
+)
+
+// Item is a session of some profile.
+type Item struct {
+	Profile string // "" for the default profile
+	Session session.Session
+}
+
+// ProfileLabel is how the item's profile is shown.
+func (it Item) ProfileLabel() string {
+	if it.Profile == "" {
+		return "default"
+	}
+	return it.Profile
+}
+
+// attentionRank orders the statuses that need the user, most urgent
+// first; other statuses don't need attention.
+var attentionRank = map[session.Status]int{
+	session.StatusInput:     0,
+	session.StatusPlanReady: 1,
+	session.StatusDone:      2,
+}
+
+// NeedsAttention reports whether a session is waiting on the user.
+func NeedsAttention(s *session.Session) bool {
+	_, ok := attentionRank[s.Status]
+	return ok
+}
+
+// Collect returns the sessions of all profiles with a running server, as
+// their sidebars last recorded them: those needing attention first, most
+// urgent and then longest waiting on top, then the others. Without all,
+// only those needing attention.
+func Collect(all bool) ([]Item, error) {
+	profiles, err := profile.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, p := range profiles {
+		if !htmux.ServerRunningAt(p.SocketPath()) {
+			continue // its panes are gone
+		}
+		state, err := session.LoadState(p.StatePath())
+		if err != nil {
+			continue
+		}
+		for _, s := range state.Sessions {
+			if s.Status == session.StatusExited || (!all && !NeedsAttention(&s)) {
+				continue
+			}
+			items = append(items, Item{Profile: p.Name, Session: s})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := &items[i].Session, &items[j].Session
+		ra, oka := attentionRank[a.Status]
+		rb, okb := attentionRank[b.Status]
+		if oka != okb {
+			return oka
+		}
+		if !oka {
+			return false // the rest keep their profile and sidebar order
+		}
+		if ra != rb {
+			return ra < rb
+		}
+		return a.StatusSince().Before(b.StatusSince())
+	})
+	return items, nil
+}
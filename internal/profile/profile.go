@@ -6,6 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/allenan/herd/internal/notify"
 )
 
 var validName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
@@ -13,57 +19,142 @@ var validName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
 // Profile represents an isolated herd environment with its own state,
 // tmux server, and claude config directory.
 type Profile struct {
-	Name           string // "" for default
-	BaseDir        string // resolved directory (e.g. ~/.herd or ~/.herd/profiles/work)
-	ClaudeConfigDir string // if set, CLAUDE_CONFIG_DIR env var for tmux server
+	Name            string     // "" for default
+	BaseDir         string     // resolved directory (e.g. ~/.herd or ~/.herd/profiles/work)
+	ClaudeConfigDir string     // if set, CLAUDE_CONFIG_DIR env var for tmux server
+	Keys            KeysConfig // global tmux key bindings
+
+	ContextWindow      int // context size in tokens, 0 = model default
+	ContextWarnPercent int // notify when a session's context passes this, 0 = never
+
+	Notifications     notify.Rules        // which events produce notifications
+	NotificationSinks []notify.SinkConfig // where they go, empty = desktop
+
+	WorktreePortBase int  // first PORT given to worktree sessions, 0 = none
+	LogOutput        bool // log every new session's output
+
+	Env         map[string]string // set on the tmux server, "" unsets
+	PathPrefix  []string          // directories put in front of PATH
+	DefaultDir  string            // where the new project picker starts, "" = launch directory
+	ClaudeArgs  []string          // flags for every new claude
+	AccentColor string            // sidebar and border color, "" = herd's own
 }
 
+// DefaultContextWarnPercent is the context usage that triggers a warning
+// unless the config says otherwise.
+const DefaultContextWarnPercent = 80
+
 // Config is the JSON config stored in a profile directory.
 type Config struct {
-	ClaudeConfigDir string `json:"claude_config_dir,omitempty"`
+	ClaudeConfigDir string      `json:"claude_config_dir,omitempty"`
+	Keys            *KeysConfig `json:"keys,omitempty"`
+
+	ContextWindow      int  `json:"context_window,omitempty"`
+	ContextWarnPercent *int `json:"context_warn_percent,omitempty"` // 0 disables the warning
+
+	Notifications     *notify.Rules       `json:"notifications,omitempty"`
+	NotificationSinks []notify.SinkConfig `json:"notification_sinks,omitempty"`
+
+	WorktreePortBase int  `json:"worktree_port_base,omitempty"`
+	LogOutput        bool `json:"log_output,omitempty"`
+
+	Env         map[string]string `json:"env,omitempty"`
+	PathPrefix  []string          `json:"path_prefix,omitempty"`
+	DefaultDir  string            `json:"default_dir,omitempty"`
+	Claude      *ClaudeConfig     `json:"claude,omitempty"`
+	AccentColor string            `json:"accent_color,omitempty"`
+}
+
+// ClaudeConfig holds the flags every new claude is started with.
+type ClaudeConfig struct {
+	Model          string   `json:"model,omitempty"`           // --model
+	PermissionMode string   `json:"permission_mode,omitempty"` // --permission-mode
+	Args           []string `json:"args,omitempty"`            // any other flags
+}
+
+// args returns the claude command line flags.
+func (c *ClaudeConfig) args() []string {
+	var args []string
+	if c.Model != "" {
+		args = append(args, "--model", c.Model)
+	}
+	if c.PermissionMode != "" {
+		args = append(args, "--permission-mode", c.PermissionMode)
+	}
+	return append(args, c.Args...)
+}
+
+// KeysConfig customizes the tmux key bindings that drive herd while focus
+// is in the viewport. Bindings map a `herd ctl` action to a tmux key name;
+// an empty key disables that action's default binding.
+type KeysConfig struct {
+	Prefix   string            `json:"prefix,omitempty"`    // tmux prefix key (default C-b)
+	TableKey string            `json:"table_key,omitempty"` // enters herd's key table after the prefix (default h)
+	Table    map[string]string `json:"table,omitempty"`     // bound in herd's key table
+	Prefixed map[string]string `json:"prefixed,omitempty"`  // bound right after the prefix, replacing tmux's own
+	Root     map[string]string `json:"root,omitempty"`      // bound without a prefix
 }
 
 // Resolve returns a Profile for the given name. An empty name returns the
 // default profile using ~/.herd/ directly (fully backward compatible).
+// A named profile's directory and default config are created on first use.
 func Resolve(name string) (*Profile, error) {
+	p, err := Load(name)
+	if err != nil || name == "" {
+		return p, err
+	}
+	if err := p.EnsureDir(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(p.ConfigPath()); os.IsNotExist(err) {
+		// Write default config on first use
+		if err := p.SaveDefaultConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Load returns a Profile for the given name with its config applied, like
+// Resolve, but writes nothing: a profile without a config keeps the
+// defaults.
+func Load(name string) (*Profile, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		home = os.Getenv("HOME")
 	}
 
-	if name == "" {
-		return &Profile{
-			Name:    "",
-			BaseDir: filepath.Join(home, ".herd"),
-		}, nil
-	}
-
-	if !validName.MatchString(name) {
-		return nil, fmt.Errorf("invalid profile name %q: must be alphanumeric with hyphens", name)
+	p := &Profile{
+		Name:               "",
+		BaseDir:            filepath.Join(home, ".herd"),
+		ContextWarnPercent: DefaultContextWarnPercent,
 	}
+	if name != "" {
+		if !validName.MatchString(name) {
+			return nil, fmt.Errorf("invalid profile name %q: must be alphanumeric with hyphens", name)
+		}
+		p = &Profile{
+			Name:            name,
+			BaseDir:         filepath.Join(profilesDir(home), name),
+			ClaudeConfigDir: filepath.Join(home, ".claude-"+name),
 
-	baseDir := filepath.Join(home, ".herd", "profiles", name)
-
-	p := &Profile{
-		Name:           name,
-		BaseDir:        baseDir,
-		ClaudeConfigDir: filepath.Join(home, ".claude-"+name),
+			ContextWarnPercent: DefaultContextWarnPercent,
+		}
 	}
 
-	if err := p.EnsureDir(); err != nil {
+	if _, err := p.loadConfig(); err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	return p, nil
+}
 
-	// Load config if it exists
-	configPath := filepath.Join(baseDir, "config.json")
-	data, err := os.ReadFile(configPath)
+// loadConfig reads config.json from the profile directory and applies it
+// to p. A missing file is returned as an os.IsNotExist error.
+func (p *Profile) loadConfig() (*Config, error) {
+	data, err := os.ReadFile(p.ConfigPath())
 	if err != nil {
 		if os.IsNotExist(err) {
-			// Write default config on first use
-			if err := p.SaveDefaultConfig(); err != nil {
-				return nil, err
-			}
-			return p, nil
+			return nil, err
 		}
 		return nil, fmt.Errorf("failed to read profile config: %w", err)
 	}
@@ -75,8 +166,278 @@ func Resolve(name string) (*Profile, error) {
 	if cfg.ClaudeConfigDir != "" {
 		p.ClaudeConfigDir = cfg.ClaudeConfigDir
 	}
+	if cfg.Keys != nil {
+		p.Keys = *cfg.Keys
+	}
+	if cfg.ContextWindow > 0 {
+		p.ContextWindow = cfg.ContextWindow
+	}
+	if cfg.ContextWarnPercent != nil {
+		p.ContextWarnPercent = *cfg.ContextWarnPercent
+	}
+	if cfg.Notifications != nil {
+		if err := cfg.Notifications.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid profile config %s: %w", p.ConfigPath(), err)
+		}
+		p.Notifications = *cfg.Notifications
+	}
+	for i, sink := range cfg.NotificationSinks {
+		if err := sink.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid profile config %s: notification_sinks[%d]: %w", p.ConfigPath(), i, err)
+		}
+	}
+	p.NotificationSinks = cfg.NotificationSinks
+	if cfg.WorktreePortBase != 0 {
+		if cfg.WorktreePortBase < 1024 || cfg.WorktreePortBase > 65535 {
+			return nil, fmt.Errorf("invalid profile config %s: worktree_port_base %d is not between 1024 and 65535", p.ConfigPath(), cfg.WorktreePortBase)
+		}
+		p.WorktreePortBase = cfg.WorktreePortBase
+	}
+	p.LogOutput = cfg.LogOutput
+	if err := p.applyEnvConfig(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid profile config %s: %w", p.ConfigPath(), err)
+	}
+	return &cfg, nil
+}
 
-	return p, nil
+var (
+	validEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	validColor   = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+)
+
+// reservedEnv are the variables herd sets itself, or has its own settings
+// for.
+var reservedEnv = map[string]string{
+	"TMUX":              "",
+	"HERD_ACTIVE":       "",
+	"PATH":              "use path_prefix",
+	"CLAUDE_CONFIG_DIR": "use claude_config_dir",
+}
+
+// permissionModes are the values claude accepts for --permission-mode.
+var permissionModes = []string{"default", "acceptEdits", "plan", "bypassPermissions", "dontAsk"}
+
+// applyEnvConfig validates and applies the settings that shape the
+// environment sessions run in.
+func (p *Profile) applyEnvConfig(cfg *Config) error {
+	for name := range cfg.Env {
+		if !validEnvName.MatchString(name) {
+			return fmt.Errorf("env: invalid variable name %q", name)
+		}
+		if hint, ok := reservedEnv[name]; ok {
+			if hint == "" {
+				return fmt.Errorf("env: %s is set by herd", name)
+			}
+			return fmt.Errorf("env: %s can't be set here, %s", name, hint)
+		}
+	}
+	p.Env = cfg.Env
+
+	p.PathPrefix = nil
+	for _, dir := range cfg.PathPrefix {
+		dir = expandHome(dir)
+		if !filepath.IsAbs(dir) || strings.Contains(dir, ":") {
+			return fmt.Errorf("path_prefix: %q is not an absolute directory", dir)
+		}
+		p.PathPrefix = append(p.PathPrefix, dir)
+	}
+
+	p.DefaultDir = ""
+	if cfg.DefaultDir != "" {
+		dir := expandHome(cfg.DefaultDir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("default_dir: %s is not a directory", dir)
+		}
+		p.DefaultDir = dir
+	}
+
+	p.ClaudeArgs = nil
+	if c := cfg.Claude; c != nil {
+		if strings.ContainsAny(c.Model, " \t") {
+			return fmt.Errorf("claude: invalid model %q", c.Model)
+		}
+		if c.PermissionMode != "" && !slices.Contains(permissionModes, c.PermissionMode) {
+			return fmt.Errorf("claude: permission_mode %q must be one of %s", c.PermissionMode, strings.Join(permissionModes, ", "))
+		}
+		p.ClaudeArgs = c.args()
+	}
+
+	if cfg.AccentColor != "" {
+		if !validColor.MatchString(cfg.AccentColor) {
+			return fmt.Errorf("accent_color %q must be a hex color like #D77757 or an ANSI color number", cfg.AccentColor)
+		}
+		if n, err := strconv.Atoi(cfg.AccentColor); err == nil && n > 255 {
+			return fmt.Errorf("accent_color %d is not an ANSI color number (0-255)", n)
+		}
+	}
+	p.AccentColor = expandHexColor(cfg.AccentColor)
+	return nil
+}
+
+// expandHexColor turns a short hex color like #abc into #aabbcc, the only
+// hex form tmux accepts.
+func expandHexColor(c string) string {
+	if len(c) != 4 || c[0] != '#' {
+		return c
+	}
+	return string([]byte{'#', c[1], c[1], c[2], c[2], c[3], c[3]})
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// List returns the profiles that exist on disk: the default one first, if
+// it has been used, then the named ones alphabetically. Only Name and
+// BaseDir are set; use Load to read a profile's config.
+func List() ([]*Profile, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+
+	var profiles []*Profile
+	base := filepath.Join(home, ".herd")
+	if _, err := os.Stat(filepath.Join(base, "state.json")); err == nil {
+		profiles = append(profiles, &Profile{BaseDir: base})
+	}
+
+	entries, err := os.ReadDir(profilesDir(home))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && validName.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profiles = append(profiles, &Profile{Name: name, BaseDir: filepath.Join(profilesDir(home), name)})
+	}
+	return profiles, nil
+}
+
+// Exists reports whether a named profile has been created. The default
+// profile always exists.
+func Exists(name string) bool {
+	if name == "" {
+		return true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	info, err := os.Stat(filepath.Join(profilesDir(home), name))
+	return err == nil && info.IsDir()
+}
+
+// Copy creates the named profile with src's config. Fields herd doesn't
+// know are kept. src's Claude config directory is only carried over when
+// it was set explicitly: a profile's own ~/.claude-<name> (or ~/.claude
+// for the default profile) is replaced by the new profile's own.
+func Copy(src *Profile, name string) (*Profile, error) {
+	if name == "" || !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid profile name %q: must be alphanumeric with hyphens", name)
+	}
+	if Exists(name) {
+		return nil, fmt.Errorf("profile %q already exists", name)
+	}
+
+	cfg := map[string]json.RawMessage{}
+	data, err := os.ReadFile(src.ConfigPath())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse profile config: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read profile config: %w", err)
+	}
+	var claudeDir string
+	if raw, ok := cfg["claude_config_dir"]; ok {
+		json.Unmarshal(raw, &claudeDir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	if claudeDir == "" || (src.Name != "" && claudeDir == filepath.Join(home, ".claude-"+src.Name)) {
+		claudeDir = filepath.Join(home, ".claude-"+name)
+	}
+	cfg["claude_config_dir"], _ = json.Marshal(claudeDir)
+
+	dst := &Profile{Name: name, BaseDir: filepath.Join(profilesDir(home), name)}
+	if err := dst.EnsureDir(); err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(dst.ConfigPath(), data, 0o644); err != nil {
+		return nil, err
+	}
+	return Resolve(name)
+}
+
+// profilesDir is where named profiles live.
+func profilesDir(home string) string {
+	return filepath.Join(home, ".herd", "profiles")
+}
+
+// SaveNotifications writes rules to the profile config, replacing only the
+// "notifications" key so settings herd doesn't know are kept. Used when
+// mutes are toggled from the sidebar.
+func (p *Profile) SaveNotifications(rules notify.Rules) error {
+	cfg := map[string]json.RawMessage{}
+	data, err := os.ReadFile(p.ConfigPath())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("failed to parse profile config: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read profile config: %w", err)
+	}
+	if cfg["notifications"], err = json.Marshal(rules); err != nil {
+		return err
+	}
+	if err := p.EnsureDir(); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	p.Notifications = rules
+	return os.WriteFile(p.ConfigPath(), data, 0o644)
+}
+
+func (p *Profile) ConfigPath() string {
+	return filepath.Join(p.BaseDir, "config.json")
+}
+
+// ClaudeDir returns the Claude Code config directory sessions in this
+// profile use: ClaudeConfigDir, or Claude's default ~/.claude.
+func (p *Profile) ClaudeDir() string {
+	if p.ClaudeConfigDir != "" {
+		return p.ClaudeConfigDir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".claude")
 }
 
 func (p *Profile) StatePath() string {
@@ -87,6 +448,10 @@ func (p *Profile) PopupResultPath() string {
 	return filepath.Join(p.BaseDir, "popup-result.json")
 }
 
+func (p *Profile) ControlSocketPath() string {
+	return filepath.Join(p.BaseDir, "control.sock")
+}
+
 func (p *Profile) TmuxSessionName() string {
 	if p.Name == "" {
 		return "herd-main"
@@ -102,6 +467,16 @@ func (p *Profile) LogPath() string {
 	return filepath.Join(p.BaseDir, "debug.log")
 }
 
+// OutputLogDir is where session output logs are written.
+func (p *Profile) OutputLogDir() string {
+	return filepath.Join(p.BaseDir, "logs")
+}
+
+// ExportDir is where sessions exported from the sidebar are written.
+func (p *Profile) ExportDir() string {
+	return filepath.Join(p.BaseDir, "exports")
+}
+
 func (p *Profile) EnsureDir() error {
 	return os.MkdirAll(p.BaseDir, 0o755)
 }
@@ -112,5 +487,5 @@ func (p *Profile) SaveDefaultConfig() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(p.BaseDir, "config.json"), data, 0o644)
+	return os.WriteFile(p.ConfigPath(), data, 0o644)
 }
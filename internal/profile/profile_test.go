@@ -0,0 +1,43 @@
+package profile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allenan/herd/internal/notify"
+)
+
+func TestSaveNotificationsKeepsOtherKeys(t *testing.T) {
+	p := &Profile{Name: "test", BaseDir: t.TempDir()}
+	orig := `{"keys": {"prefix": "C-a"}, "future_setting": {"x": 1}, "notifications": {"skip_visible": true}}`
+	if err := os.WriteFile(p.ConfigPath(), []byte(orig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.SaveNotifications(notify.Rules{MutedProjects: []string{"web"}}); err != nil {
+		t.Fatalf("SaveNotifications: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.BaseDir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("config is not JSON: %v\n%s", err, data)
+	}
+	for key, want := range map[string]string{
+		"keys":           `{"prefix":"C-a"}`,
+		"future_setting": `{"x":1}`,
+		"notifications":  `{"muted_projects":["web"]}`,
+	} {
+		var compact map[string]any
+		json.Unmarshal(cfg[key], &compact)
+		got, _ := json.Marshal(compact)
+		if string(got) != want {
+			t.Errorf("%s = %s, want %s", key, got, want)
+		}
+	}
+}
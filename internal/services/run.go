@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Backoff between restarts doubles from minBackoff up to maxBackoff, and
+// resets once a run has stayed up for stableAfter.
+const (
+	minBackoff  = time.Second
+	maxBackoff  = 30 * time.Second
+	stableAfter = time.Minute
+)
+
+// Run runs svc in the foreground from the project directory dir, with the
+// terminal's stdio, restarting it after crashes. A clean exit ends it, as
+// does Ctrl-C, which reaches the whole foreground process group.
+func Run(dir string, svc Service) error {
+	backoff := minBackoff
+	for {
+		cmd := exec.Command("sh", "-c", svc.Command)
+		cmd.Dir = filepath.Join(dir, svc.Cwd)
+		cmd.Env = os.Environ()
+		for k, v := range svc.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+		fmt.Fprintf(os.Stderr, "[herd] starting %s: %s\n", svc.Name, svc.Command)
+		started := time.Now()
+		err := cmd.Run()
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "[herd] %s exited\n", svc.Name)
+			return nil
+		}
+		if !svc.Restarts() {
+			return fmt.Errorf("%s: %w", svc.Name, err)
+		}
+		if time.Since(started) > stableAfter {
+			backoff = minBackoff
+		}
+		fmt.Fprintf(os.Stderr, "[herd] %s crashed (%v), restarting in %s\n", svc.Name, err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
@@ -0,0 +1,116 @@
+// Package services reads the services a project declares, so herd can run
+// each in its own terminal.
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFile is the project file services are declared in. A Procfile is
+// read when it doesn't exist.
+const ManifestFile = ".herd.json"
+
+// Service is one long-running process of a project.
+type Service struct {
+	Name     string            `json:"name"`
+	Command  string            `json:"command"`             // run with sh -c
+	Cwd      string            `json:"cwd,omitempty"`       // relative to the project directory
+	Env      map[string]string `json:"env,omitempty"`       // added to the environment
+	Port     int               `json:"port,omitempty"`      // expected port; ready once it listens
+	ReadyCmd string            `json:"ready_cmd,omitempty"` // ready once this exits 0 (e.g. a curl health check)
+	Restart  *bool             `json:"restart,omitempty"`   // restart when it crashes (default true)
+}
+
+// Restarts reports whether the service is restarted after a crash.
+func (s Service) Restarts() bool {
+	return s.Restart == nil || *s.Restart
+}
+
+// Manifest is the services section of a project's .herd.json.
+type Manifest struct {
+	Services []Service `json:"services"`
+}
+
+// Load reads the manifest in dir: .herd.json, or a Procfile.
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return loadProcfile(dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	return &m, nil
+}
+
+// loadProcfile reads "name: command" lines from a Procfile.
+func loadProcfile(dir string) (*Manifest, error) {
+	f, err := os.Open(filepath.Join(dir, "Procfile"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no %s or Procfile in %s", ManifestFile, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Procfile: %w", err)
+	}
+	defer f.Close()
+
+	var m Manifest
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, command, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		m.Services = append(m.Services, Service{Name: strings.TrimSpace(name), Command: strings.TrimSpace(command)})
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("Procfile: %w", err)
+	}
+	return &m, nil
+}
+
+func (m *Manifest) validate() error {
+	if len(m.Services) == 0 {
+		return fmt.Errorf("no services declared")
+	}
+	seen := make(map[string]bool)
+	for i, s := range m.Services {
+		if s.Name == "" {
+			return fmt.Errorf("service %d has no name", i+1)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("service %q is declared twice", s.Name)
+		}
+		seen[s.Name] = true
+		if s.Command == "" {
+			return fmt.Errorf("service %q has no command", s.Name)
+		}
+	}
+	return nil
+}
+
+// Find returns the service called name.
+func (m *Manifest) Find(name string) (Service, bool) {
+	for _, s := range m.Services {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Service{}, false
+}
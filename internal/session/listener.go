@@ -0,0 +1,74 @@
+package session
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Listener is a TCP port a terminal's processes are listening on.
+type Listener struct {
+	Port    int    `json:"port"`
+	Addr    string `json:"addr"`              // bind address, e.g. "127.0.0.1" or "::"
+	Process string `json:"process,omitempty"` // owning process name, e.g. "node"
+}
+
+// PortConflict records that a terminal can't get a port because another
+// herd session, possibly in another profile, is listening on it.
+type PortConflict struct {
+	Port    int    `json:"port"`
+	Holder  string `json:"holder"`            // display name of the session holding the port
+	Profile string `json:"profile,omitempty"` // the holder's profile, "" when it is this one
+}
+
+// String describes the conflict, e.g. ":3000 held by feature-x (work)".
+func (c *PortConflict) String() string {
+	s := fmt.Sprintf(":%d held by %s", c.Port, c.Holder)
+	if c.Profile != "" {
+		s += " (" + c.Profile + ")"
+	}
+	return s
+}
+
+// URL returns an http URL for the listener, using localhost for wildcard
+// binds.
+func (l Listener) URL() string {
+	host := l.Addr
+	switch host {
+	case "", "*", "0.0.0.0", "::":
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(l.Port))
+}
+
+// SortListeners orders listeners by port, then address.
+func SortListeners(ls []Listener) {
+	sort.Slice(ls, func(i, j int) bool {
+		if ls[i].Port != ls[j].Port {
+			return ls[i].Port < ls[j].Port
+		}
+		return ls[i].Addr < ls[j].Addr
+	})
+}
+
+// Ports returns the distinct ports the session listens on, lowest first.
+func (s *Session) Ports() []int {
+	var ports []int
+	for _, l := range s.Listeners {
+		if len(ports) == 0 || ports[len(ports)-1] != l.Port {
+			ports = append(ports, l.Port)
+		}
+	}
+	return ports
+}
+
+// portsLabel renders ports compactly for the sidebar: ":3000 :8080".
+func portsLabel(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = fmt.Sprintf(":%d", p)
+	}
+	return strings.Join(parts, " ")
+}
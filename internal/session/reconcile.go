@@ -38,6 +38,7 @@ func (s *State) Reconcile(livePanes []LivePane, layoutPaneIDs map[string]bool) b
 		if liveSet[sess.TmuxPaneID] {
 			valid = append(valid, sess)
 		} else {
+			s.Archive(sess)
 			changed = true
 		}
 	}
@@ -8,9 +8,9 @@ import (
 type Status string
 
 const (
-	StatusRunning Status = "running"
-	StatusInput   Status = "input"
-	StatusIdle    Status = "idle"
+	StatusRunning   Status = "running"
+	StatusInput     Status = "input"
+	StatusIdle      Status = "idle"
 	StatusDone      Status = "done"
 	StatusPlanReady Status = "plan_ready"
 	StatusExited    Status = "exited"
@@ -21,32 +21,74 @@ const (
 type SessionType string
 
 const (
-	TypeClaude   SessionType = ""         // zero value = backward compat
+	TypeClaude   SessionType = "" // zero value = backward compat
 	TypeTerminal SessionType = "terminal"
 )
 
 type Session struct {
-	ID             string      `json:"id"`
-	TmuxPaneID     string      `json:"tmux_pane_id"`
-	Project        string      `json:"project"`
-	Name           string      `json:"name"`
-	Title          string      `json:"title,omitempty"`
-	Dir            string      `json:"dir"`
-	CreatedAt      time.Time   `json:"created_at"`
-	Status         Status      `json:"status"`
-	Type           SessionType `json:"type,omitempty"`
-	ServicePort    int         `json:"service_port,omitempty"`
-	IsWorktree     bool        `json:"is_worktree,omitempty"`
-	WorktreeBranch string      `json:"worktree_branch,omitempty"`
+	ID             string         `json:"id"`
+	TmuxPaneID     string         `json:"tmux_pane_id"`
+	Project        string         `json:"project"`
+	Name           string         `json:"name"`
+	Title          string         `json:"title,omitempty"`
+	Dir            string         `json:"dir"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Status         Status         `json:"status"`
+	Type           SessionType    `json:"type,omitempty"`
+	ServicePort    int            `json:"service_port,omitempty"`  // lowest non-ephemeral listening port
+	Listeners      []Listener     `json:"listeners,omitempty"`     // every listening socket, by port
+	Service        *ServiceInfo   `json:"service,omitempty"`       // set on terminals running a project service
+	Command        string         `json:"command,omitempty"`       // last command line run in a terminal, for restarts
+	RestartingAt   time.Time      `json:"restarting_at,omitempty"` // set while a restarted terminal comes back up
+	RestartPort    int            `json:"restart_port,omitempty"`  // port the restarted command is expected to listen on again
+	Port           int            `json:"port,omitempty"`          // PORT env given to a worktree session and its terminals
+	PortConflict   *PortConflict  `json:"port_conflict,omitempty"` // port another herd session holds
+	Logging        bool           `json:"logging,omitempty"`       // output is piped to a log under the profile
+	IsWorktree     bool           `json:"is_worktree,omitempty"`
+	WorktreeBranch string         `json:"worktree_branch,omitempty"`
+	ParentID       string         `json:"parent_id,omitempty"`       // Claude session a terminal is paired with
+	LastActiveAt   time.Time      `json:"last_active_at,omitempty"`  // last status change or visit
+	Tags           []string       `json:"tags,omitempty"`            // user-defined labels, e.g. "review"
+	Transcripts    []string       `json:"transcripts,omitempty"`     // Claude transcript IDs attributed to this session
+	ContextPercent int            `json:"context_percent,omitempty"` // context window used by the latest request
+	Timeline       []StatusChange `json:"timeline,omitempty"`        // recent status transitions, oldest first
+}
+
+// ActivityTime returns when the session was last active, falling back to
+// its creation time for sessions saved before activity was tracked.
+func (s *Session) ActivityTime() time.Time {
+	if s.LastActiveAt.IsZero() {
+		return s.CreatedAt
+	}
+	return s.LastActiveAt
+}
+
+// ServiceInfo marks a terminal started for a service declared in the
+// project's manifest.
+type ServiceInfo struct {
+	Name     string `json:"name"`
+	Port     int    `json:"port,omitempty"`      // expected port
+	ReadyCmd string `json:"ready_cmd,omitempty"` // readiness check, run in Dir
+	Ready    bool   `json:"ready,omitempty"`
 }
 
 // DisplayName returns a human-readable name for the session.
-// For terminals: port number if a service is detected, pane_current_command
-// when running, or "shell" when idle. For Claude sessions: Title if set
+// For service terminals: the service name and its ports. For other
+// terminals: the listening ports if a service is detected,
+// pane_current_command when running, or "shell" when idle. For Claude sessions: Title if set
 // (from Claude Code's terminal title), otherwise the static Name.
 func (s *Session) DisplayName() string {
 	if s.Type == TypeTerminal {
+		if s.Service != nil {
+			if ports := s.Ports(); len(ports) > 0 {
+				return s.Service.Name + " " + portsLabel(ports)
+			}
+			return s.Service.Name
+		}
 		if s.ServicePort > 0 {
+			if ports := s.Ports(); len(ports) > 0 {
+				return portsLabel(ports)
+			}
 			return fmt.Sprintf(":%d", s.ServicePort)
 		}
 		if s.Title != "" {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -15,8 +16,18 @@ type State struct {
 	LastActiveSession string   `json:"last_active_session"`
 	ViewportPaneID   string    `json:"viewport_pane_id"`
 	SidebarPaneID    string    `json:"sidebar_pane_id"`
+	ViewportSlots    []string  `json:"viewport_slots,omitempty"` // all viewport panes in layout order, set only when split
+	ActiveSlot       int       `json:"active_slot,omitempty"`    // index into ViewportSlots that SwitchTo targets
+	DockedPaneID     string    `json:"docked_pane_id,omitempty"` // paired terminal joined below the viewport
+	PairedHidden     bool      `json:"paired_hidden,omitempty"`  // user toggled paired terminals off
+	History          []string  `json:"history,omitempty"`        // switched-to session IDs, most recent first
+	SidebarView      string    `json:"sidebar_view,omitempty"`   // sidebar grouping/sort mode ("" = by project)
+	Ended            []EndedSession `json:"ended,omitempty"`   // timelines of removed sessions, oldest first
 }
 
+// maxHistory bounds the MRU session history.
+const maxHistory = 50
+
 func DefaultStatePath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -97,19 +108,75 @@ func backupState(path string) {
 	io.Copy(dst, src)
 }
 
+// InViewport reports whether paneID is currently shown in any viewport slot.
+func (s *State) InViewport(paneID string) bool {
+	if paneID == "" {
+		return false
+	}
+	if paneID == s.ViewportPaneID {
+		return true
+	}
+	for _, id := range s.ViewportSlots {
+		if id == paneID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *State) AddSession(sess Session) {
 	s.Sessions = append(s.Sessions, sess)
 }
 
 func (s *State) RemoveSession(id string) {
+	s.forgetHistory(id)
 	for i, sess := range s.Sessions {
 		if sess.ID == id {
+			s.Archive(sess)
 			s.Sessions = append(s.Sessions[:i], s.Sessions[i+1:]...)
 			return
 		}
 	}
 }
 
+// RecordVisit moves a session to the front of the MRU history.
+func (s *State) RecordVisit(id string) {
+	if sess := s.FindByID(id); sess != nil {
+		sess.LastActiveAt = time.Now()
+	}
+	s.forgetHistory(id)
+	s.History = append([]string{id}, s.History...)
+	if len(s.History) > maxHistory {
+		s.History = s.History[:maxHistory]
+	}
+}
+
+// forgetHistory drops a session from the MRU history.
+func (s *State) forgetHistory(id string) {
+	kept := s.History[:0]
+	for _, h := range s.History {
+		if h != id {
+			kept = append(kept, h)
+		}
+	}
+	s.History = kept
+}
+
+// PairedTerminals returns the terminals attached to the given Claude session,
+// in slice order.
+func (s *State) PairedTerminals(parentID string) []*Session {
+	if parentID == "" {
+		return nil
+	}
+	var out []*Session
+	for i := range s.Sessions {
+		if s.Sessions[i].ParentID == parentID && s.Sessions[i].Type == TypeTerminal {
+			out = append(out, &s.Sessions[i])
+		}
+	}
+	return out
+}
+
 func (s *State) FindByID(id string) *Session {
 	for i := range s.Sessions {
 		if s.Sessions[i].ID == id {
@@ -119,6 +186,27 @@ func (s *State) FindByID(id string) *Session {
 	return nil
 }
 
+// FindByIDPrefix returns the session whose ID is id or, failing that, the
+// only session whose ID starts with id (as shown by `herd ls`).
+func (s *State) FindByIDPrefix(id string) (*Session, error) {
+	if sess := s.FindByID(id); sess != nil {
+		return sess, nil
+	}
+	var found *Session
+	for i := range s.Sessions {
+		if id != "" && strings.HasPrefix(s.Sessions[i].ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("session ID %q is ambiguous", id)
+			}
+			found = &s.Sessions[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no session with ID %q", id)
+	}
+	return found, nil
+}
+
 func (s *State) FindByPaneID(paneID string) *Session {
 	for i := range s.Sessions {
 		if s.Sessions[i].TmuxPaneID == paneID {
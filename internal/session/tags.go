@@ -0,0 +1,46 @@
+package session
+
+import "strings"
+
+// ParseTags splits user input into tags. Tags are separated by spaces or
+// commas; a leading '#' is dropped and duplicates are removed, keeping the
+// first spelling.
+func ParseTags(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	var tags []string
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		tag := strings.TrimLeft(f, "#")
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HasTag reports whether the session has the given tag, ignoring case.
+func (s *Session) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTagPrefix reports whether any tag starts with prefix, ignoring case,
+// so filters match while the tag is still being typed.
+func (s *Session) HasTagPrefix(prefix string) bool {
+	prefix = strings.ToLower(prefix)
+	for _, t := range s.Tags {
+		if strings.HasPrefix(strings.ToLower(t), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package session
+
+import "time"
+
+// maxTimeline bounds the status changes kept per session.
+const maxTimeline = 100
+
+// StatusChange records when a session entered a status.
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// SetStatus moves the session to status st at time at, recording the
+// transition in its timeline. Setting the current status is a no-op.
+func (s *Session) SetStatus(st Status, at time.Time) {
+	if s.Status == st {
+		return
+	}
+	s.Status = st
+	s.LastActiveAt = at
+	s.Timeline = append(s.Timeline, StatusChange{Status: st, At: at})
+	if n := len(s.Timeline); n > maxTimeline {
+		s.Timeline = append([]StatusChange(nil), s.Timeline[n-maxTimeline:]...)
+	}
+}
+
+// StatusSince returns when the session entered its current status. Sessions
+// with no recorded transition have been in it since creation.
+func (s *Session) StatusSince() time.Time {
+	if n := len(s.Timeline); n > 0 && s.Timeline[n-1].Status == s.Status {
+		return s.Timeline[n-1].At
+	}
+	return s.CreatedAt
+}
+
+// LastRun returns how long the most recent completed running period lasted.
+func (s *Session) LastRun() (time.Duration, bool) {
+	for i := len(s.Timeline) - 2; i >= 0; i-- {
+		if s.Timeline[i].Status == StatusRunning {
+			return s.Timeline[i+1].At.Sub(s.Timeline[i].At), true
+		}
+	}
+	return 0, false
+}
+
+// maxEnded bounds the timelines kept for removed sessions.
+const maxEnded = 200
+
+// EndedSession keeps the timeline of a session after it is removed, so
+// herd log can still show it.
+type EndedSession struct {
+	ID       string         `json:"id"`
+	Project  string         `json:"project"`
+	Name     string         `json:"name"`
+	EndedAt  time.Time      `json:"ended_at"`
+	Timeline []StatusChange `json:"timeline"`
+}
+
+// Archive records the timeline of a session that is being removed from
+// the state. Sessions with no recorded changes are not kept.
+func (s *State) Archive(sess Session) {
+	if len(sess.Timeline) == 0 {
+		return
+	}
+	s.Ended = append(s.Ended, EndedSession{
+		ID:       sess.ID,
+		Project:  sess.Project,
+		Name:     sess.DisplayName(),
+		EndedAt:  time.Now(),
+		Timeline: sess.Timeline,
+	})
+	if n := len(s.Ended); n > maxEnded {
+		s.Ended = append([]EndedSession(nil), s.Ended[n-maxEnded:]...)
+	}
+}
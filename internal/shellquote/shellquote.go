@@ -0,0 +1,14 @@
+// Package shellquote builds /bin/sh command lines from argument lists.
+package shellquote
+
+import "strings"
+
+// Join quotes args into a single /bin/sh command line, for tmux commands
+// like run-shell and tools that take a shell string.
+func Join(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
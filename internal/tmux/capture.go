@@ -3,6 +3,7 @@ package tmux
 import (
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/allenan/herd/internal/session"
@@ -93,7 +94,7 @@ func DetectAllStatuses(sessions []session.Session) bool {
 	for i := range sessions {
 		newStatus := DetectStatus(sessions[i].TmuxPaneID)
 		if sessions[i].Status != newStatus {
-			sessions[i].Status = newStatus
+			sessions[i].SetStatus(newStatus, time.Now())
 			changed = true
 		}
 	}
@@ -0,0 +1,176 @@
+package tmux
+
+import (
+	"fmt"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// dockHeight is the size of the paired-terminal split below the viewport.
+const dockHeight = "30%"
+
+// CreatePairedTerminal opens a terminal attached to a Claude session and
+// switches to it, which shows it docked below its parent.
+func (m *Manager) CreatePairedTerminal(parentID string) (*session.Session, error) {
+	m.reloadState()
+
+	parent := m.State.FindByID(parentID)
+	if parent == nil {
+		return nil, fmt.Errorf("session %s not found", parentID)
+	}
+	if parent.Type == session.TypeTerminal {
+		return nil, fmt.Errorf("terminals can only be paired with Claude sessions")
+	}
+
+	term, err := m.newTerminal(parent.Dir, parent.Project, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	termID := term.ID
+	if err := m.SwitchTo(termID); err != nil {
+		m.State.Save(m.StatePath)
+		return nil, err
+	}
+	return m.State.FindByID(termID), nil
+}
+
+// SetParent pairs an existing terminal with a Claude session, or unpairs
+// it when parentID is empty. The dock follows the change right away.
+func (m *Manager) SetParent(termID, parentID string) error {
+	m.reloadState()
+
+	term := m.State.FindByID(termID)
+	if term == nil {
+		return fmt.Errorf("session %s not found", termID)
+	}
+	if term.Type != session.TypeTerminal {
+		return fmt.Errorf("only terminals can be paired")
+	}
+	if parentID != "" {
+		parent := m.State.FindByID(parentID)
+		if parent == nil {
+			return fmt.Errorf("session %s not found", parentID)
+		}
+		if parent.Type == session.TypeTerminal {
+			return fmt.Errorf("terminals can only be paired with Claude sessions")
+		}
+	}
+
+	if term.TmuxPaneID == m.State.DockedPaneID {
+		m.undock()
+	}
+	term.ParentID = parentID
+	debugLog.Printf("SetParent: terminal %s parent=%q", termID, parentID)
+	m.syncDock()
+	return m.State.Save(m.StatePath)
+}
+
+// PairCandidate picks the Claude session a terminal would be paired with:
+// the one of its project switched to most recently, or else its first.
+func (m *Manager) PairCandidate(termID string) *session.Session {
+	term := m.State.FindByID(termID)
+	if term == nil {
+		return nil
+	}
+	for _, id := range m.State.History {
+		if s := m.State.FindByID(id); s != nil && s.Type != session.TypeTerminal && s.Project == term.Project {
+			return s
+		}
+	}
+	for i := range m.State.Sessions {
+		if s := &m.State.Sessions[i]; s.Type != session.TypeTerminal && s.Project == term.Project {
+			return s
+		}
+	}
+	return nil
+}
+
+// TogglePaired shows or hides paired terminals below their parent.
+func (m *Manager) TogglePaired() {
+	m.reloadState()
+	m.State.PairedHidden = !m.State.PairedHidden
+	m.syncDock()
+	m.State.Save(m.StatePath)
+}
+
+// syncDock makes the dock match the session in the active viewport slot.
+func (m *Manager) syncDock() {
+	m.dock("")
+}
+
+// dock joins a paired terminal of the active viewport session below it, or
+// undocks when that session has none (or paired terminals are hidden).
+// preferID picks which paired terminal to show; otherwise the currently
+// docked one is kept, falling back to the first.
+func (m *Manager) dock(preferID string) {
+	var want *session.Session
+	if !m.State.PairedHidden {
+		if parent := m.State.FindByPaneID(m.State.ViewportPaneID); parent != nil {
+			paired := m.State.PairedTerminals(parent.ID)
+			for _, t := range paired {
+				if t.ID == preferID || (preferID == "" && t.TmuxPaneID == m.State.DockedPaneID) {
+					want = t
+				}
+			}
+			if want == nil && len(paired) > 0 {
+				want = paired[0]
+			}
+		}
+	}
+
+	if want != nil && want.TmuxPaneID == m.State.DockedPaneID {
+		return
+	}
+	m.undock()
+	if want == nil {
+		return
+	}
+
+	if err := TmuxRun(
+		"join-pane", "-d", "-v", "-l", dockHeight,
+		"-s", want.TmuxPaneID,
+		"-t", m.State.ViewportPaneID,
+	); err != nil {
+		debugLog.Printf("dock: join-pane %s failed: %v", want.TmuxPaneID, err)
+		return
+	}
+	m.State.DockedPaneID = want.TmuxPaneID
+	debugLog.Printf("dock: docked %s below %s", want.TmuxPaneID, m.State.ViewportPaneID)
+}
+
+// undock moves the docked terminal back into its own window.
+func (m *Manager) undock() {
+	paneID := m.State.DockedPaneID
+	if paneID == "" {
+		return
+	}
+	m.State.DockedPaneID = ""
+	if !paneExists(paneID) {
+		return
+	}
+	windowName := "term"
+	if t := m.State.FindByPaneID(paneID); t != nil {
+		windowName = sessionWindowLabel(t)
+	}
+	if err := TmuxRun("break-pane", "-d", "-s", paneID, "-n", windowName); err != nil {
+		debugLog.Printf("undock: break-pane %s failed: %v", paneID, err)
+		return
+	}
+	debugLog.Printf("undock: moved %s to its own window", paneID)
+}
+
+// KillSessionWithPaired kills a Claude session along with every terminal
+// paired with it.
+func (m *Manager) KillSessionWithPaired(sessionID string) error {
+	m.reloadState()
+	var ids []string
+	for _, t := range m.State.PairedTerminals(sessionID) {
+		ids = append(ids, t.ID)
+	}
+	for _, id := range ids {
+		if err := m.KillSession(id); err != nil {
+			debugLog.Printf("KillSessionWithPaired: kill %s failed: %v", id, err)
+		}
+	}
+	return m.KillSession(sessionID)
+}
@@ -0,0 +1,106 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/allenan/herd/internal/export"
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/usage"
+)
+
+// ExportSession builds a shareable document of a session: its Claude
+// transcripts when there are any, otherwise (or with scrollback) the
+// full tmux scrollback of its pane.
+func ExportSession(s *session.Session, tracker *usage.Tracker, scrollback bool) (*export.Doc, error) {
+	var doc *export.Doc
+	source := "transcript"
+	if !scrollback && tracker != nil && s.Type == session.TypeClaude {
+		var paths []string
+		var model string
+		for _, id := range s.Transcripts {
+			if tr, ok := tracker.Get(s.Dir, id); ok {
+				paths = append(paths, tr.Path)
+				model = tr.Model
+			}
+		}
+		if len(paths) > 0 {
+			d, err := export.FromTranscripts(paths)
+			if err != nil {
+				return nil, err
+			}
+			if len(d.Entries) > 0 {
+				doc = d
+				if model != "" {
+					doc.Meta = append(doc.Meta, export.Field{Name: "model", Value: model})
+				}
+			}
+		}
+	}
+	if doc == nil {
+		content, err := TmuxRunOutput("capture-pane", "-p", "-J", "-S", "-", "-t", s.TmuxPaneID)
+		if err != nil {
+			return nil, fmt.Errorf("no transcript, and failed to capture scrollback: %w", err)
+		}
+		doc = export.FromScrollback(content)
+		source = "scrollback"
+	}
+
+	doc.Title = s.DisplayName()
+	meta := []export.Field{{Name: "project", Value: s.Project}}
+	if s.WorktreeBranch != "" {
+		meta = append(meta, export.Field{Name: "branch", Value: s.WorktreeBranch})
+	}
+	meta = append(meta,
+		export.Field{Name: "dir", Value: s.Dir},
+		export.Field{Name: "source", Value: source},
+	)
+	meta = append(meta, doc.Meta...)
+	doc.Meta = append(meta, export.Field{Name: "exported", Value: time.Now().Format("2006-01-02 15:04")})
+	return doc, nil
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Export writes a copy of a session to ExportDir as Markdown or HTML,
+// by format, and returns the file's path. It reads transcripts and
+// captures the pane, so it runs in a goroutine; it doesn't touch the
+// manager's state.
+func (m *Manager) Export(s session.Session, format string) (string, error) {
+	if m.ExportDir == "" {
+		return "", fmt.Errorf("exports are not set up")
+	}
+	ext := ".md"
+	if format == "html" {
+		ext = ".html"
+	}
+	doc, err := ExportSession(&s, m.Usage, false)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(s.Project+"-"+s.DisplayName(), "-"), "-")
+	path := filepath.Join(m.ExportDir, fmt.Sprintf("%s-%s%s", name, time.Now().Format("20060102-150405"), ext))
+	if err := os.MkdirAll(m.ExportDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to export: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to export: %w", err)
+	}
+	if format == "html" {
+		err = doc.HTML(f)
+	} else {
+		err = doc.Markdown(f)
+	}
+	if err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to export: %w", err)
+	}
+	debugLog.Printf("Export: session %s to %s", s.ID, path)
+	return path, f.Close()
+}
@@ -0,0 +1,48 @@
+package tmux
+
+import (
+	"fmt"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// SwitchToPrevious switches to the most recently visited session other than
+// the active one, so repeated use toggles between the last two.
+func (m *Manager) SwitchToPrevious() error {
+	m.reloadState()
+	for _, id := range m.State.History {
+		if id != m.State.LastActiveSession && m.State.FindByID(id) != nil {
+			return m.SwitchTo(id)
+		}
+	}
+	return fmt.Errorf("no previous session")
+}
+
+// JumpToNext switches to the next session whose status is one of statuses.
+// Candidates are ordered by the position of their status in statuses, then
+// by sidebar order; repeated calls cycle through them starting after the
+// active session.
+func (m *Manager) JumpToNext(statuses ...session.Status) error {
+	m.reloadState()
+
+	var queue []string
+	for _, st := range statuses {
+		for _, s := range m.State.Sessions {
+			if s.Status == st {
+				queue = append(queue, s.ID)
+			}
+		}
+	}
+	if len(queue) == 0 {
+		return fmt.Errorf("nothing needs attention")
+	}
+
+	next := queue[0]
+	for i, id := range queue {
+		if id == m.State.LastActiveSession {
+			next = queue[(i+1)%len(queue)]
+			break
+		}
+	}
+	return m.SwitchTo(next)
+}
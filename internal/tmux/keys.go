@@ -0,0 +1,167 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/allenan/herd/internal/control"
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/shellquote"
+)
+
+// keyTable is herd's own tmux key table, entered with the prefix and then
+// defaultTableKey. Keeping herd's bindings there leaves tmux's prefix
+// defaults alone; the config can still bind actions in the prefix table.
+const (
+	keyTable        = "herd"
+	defaultTableKey = "h"
+)
+
+// defaultTableKeys are bound in herd's key table.
+var defaultTableKeys = map[string]string{
+	control.ActionNew:      "n",
+	control.ActionTerminal: "t",
+	control.ActionNext:     "j",
+	control.ActionPrev:     "k",
+	control.ActionLast:     "o",
+	control.ActionInput:    "i",
+	control.ActionDone:     "u",
+	control.ActionKill:     "x",
+}
+
+// defaultRootKeys work without a prefix.
+var defaultRootKeys = map[string]string{
+	control.ActionLast:  "M-o",
+	control.ActionInput: "M-i",
+	control.ActionDone:  "M-u",
+}
+
+// boundKeysOption records what BindKeys bound, so a later call can remove
+// bindings dropped from the config.
+const boundKeysOption = "@herd-bound-keys"
+
+// savedPrefixOption holds the prefix the server had before BindKeys set
+// the configured one, to restore once the config stops setting it.
+const savedPrefixOption = "@herd-saved-prefix"
+
+// BindKeys installs the global key bindings that drive the sidebar through
+// `herd ctl`, so sessions can be managed while focus stays in the viewport.
+// It is safe to call on every attach; bindings from a previous config are
+// removed first.
+func BindKeys(keys profile.KeysConfig) error {
+	selfBin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	ctlBase := []string{selfBin}
+	if profileName != "" {
+		ctlBase = append(ctlBase, "--profile", profileName)
+	}
+
+	if out, err := TmuxRunOutput("show-options", "-gqv", boundKeysOption); err == nil {
+		for _, tk := range strings.Fields(out) {
+			if table, key, ok := strings.Cut(tk, ":"); ok {
+				TmuxRun("unbind-key", "-T", table, key)
+			}
+		}
+	}
+
+	if err := applyPrefix(keys.Prefix); err != nil {
+		return err
+	}
+
+	var bound []string
+	tableKey := keys.TableKey
+	if tableKey == "" {
+		tableKey = defaultTableKey
+	}
+	if err := TmuxRun("bind-key", "-T", "prefix", tableKey, "switch-client", "-T", keyTable); err != nil {
+		return fmt.Errorf("invalid table key %q: %w", tableKey, err)
+	}
+	bound = append(bound, "prefix:"+tableKey)
+
+	for _, table := range []struct {
+		name      string
+		defaults  map[string]string
+		overrides map[string]string
+	}{
+		{keyTable, defaultTableKeys, keys.Table},
+		{"prefix", nil, keys.Prefixed},
+		{"root", defaultRootKeys, keys.Root},
+	} {
+		for action, key := range mergeKeys(table.defaults, table.overrides) {
+			if !control.Valid(action) {
+				debugLog.Printf("BindKeys: ignoring unknown action %q", action)
+				continue
+			}
+			cmd := []string{"run-shell", "-b", shellquote.Join(append(ctlBase, "ctl", action)...)}
+			if action == control.ActionKill {
+				cmd = []string{"confirm-before", "-p", "kill session and its paired terminals? (y/n)", tmuxQuote(cmd...)}
+			}
+			if err := TmuxRun(append([]string{"bind-key", "-T", table.name, key}, cmd...)...); err != nil {
+				debugLog.Printf("BindKeys: bind %s %s failed: %v", table.name, key, err)
+				continue
+			}
+			bound = append(bound, table.name+":"+key)
+		}
+	}
+	TmuxRun("set-option", "-g", boundKeysOption, strings.Join(bound, " "))
+	return nil
+}
+
+// applyPrefix sets the configured prefix key, or restores the one it
+// replaced when prefix is empty.
+func applyPrefix(prefix string) error {
+	saved, _ := TmuxRunOutput("show-options", "-gqv", savedPrefixOption)
+	saved = strings.TrimSpace(saved)
+	if prefix == "" {
+		if saved == "" {
+			return nil
+		}
+		if err := TmuxRun("set-option", "-g", "prefix", saved); err != nil {
+			return fmt.Errorf("failed to restore prefix key %q: %w", saved, err)
+		}
+		return TmuxRun("set-option", "-gu", savedPrefixOption)
+	}
+
+	if saved == "" {
+		current, err := TmuxRunOutput("show-options", "-gv", "prefix")
+		if err != nil {
+			return fmt.Errorf("failed to read prefix key: %w", err)
+		}
+		TmuxRun("set-option", "-g", savedPrefixOption, strings.TrimSpace(current))
+	}
+	if err := TmuxRun("set-option", "-g", "prefix", prefix); err != nil {
+		return fmt.Errorf("invalid prefix key %q: %w", prefix, err)
+	}
+	return nil
+}
+
+// mergeKeys applies config overrides (action -> key) on top of defaults.
+// An empty key removes the action.
+func mergeKeys(defaults, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaults))
+	for action, key := range defaults {
+		merged[action] = key
+	}
+	for action, key := range overrides {
+		if key == "" {
+			delete(merged, action)
+		} else {
+			merged[action] = key
+		}
+	}
+	return merged
+}
+
+// tmuxQuote joins args into a single tmux command string, double-quoting
+// each so it survives tmux's own parser (e.g. inside confirm-before).
+func tmuxQuote(args ...string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = `"` + r.Replace(a) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
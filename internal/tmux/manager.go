@@ -1,24 +1,55 @@
 package tmux
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/allenan/herd/internal/notify"
+	"github.com/allenan/herd/internal/outlog"
+	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/services"
 	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/shellquote"
+	"github.com/allenan/herd/internal/usage"
 	"github.com/allenan/herd/internal/worktree"
 	gotmux "github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/google/uuid"
 )
 
 type Manager struct {
-	Client      *gotmux.Tmux
-	State       *session.State
-	StatePath   string
-	Notifier    notify.Notifier // nil = no notifications
-	notifyReady bool            // set after first RefreshStatus to avoid startup spam
+	Client    *gotmux.Tmux
+	State     *session.State
+	StatePath string
+	Notifier  notify.Notifier // nil = no notifications
+	Usage     *usage.Tracker  // nil = no token/cost tracking
+
+	ContextWindow      int  // tokens, 0 = usage.DefaultContextWindow
+	ContextWarnPercent int  // notify when context usage crosses this, 0 = never
+	notifyReady        bool // set after first RefreshStatus to avoid startup spam
+
+	Rules        notify.Rules             // mutes, quiet hours and escalation for Notifier
+	SaveRules    func(notify.Rules) error // persists rule changes, nil = keep in memory
+	lastNotified map[string]time.Time     // session ID → last "needs input" notification
+
+	Peers    map[string]*profile.Profile // other profiles by name, checked for port conflicts
+	PortBase int                         // first PORT given to worktree sessions, 0 = none
+
+	LogDir    string // where session output logs go, "" = no logging
+	LogOutput bool   // log the output of new sessions from the start
+	ExportDir string // where the sidebar writes exported sessions
+
+	usageCache map[string]usage.Usage // session ID → usage from the last ApplyUsage
+	ready      prober[bool]           // background ready_cmd checks of services
+	scans      map[string]*paneScan   // session ID → last port conflict scan of a terminal
+	jobs       prober[string]         // background reads of terminals' command lines
+
+	previewPaneID    string // temporary preview split, "" when closed
+	previewSessionID string // session shown in the preview split
 }
 
 func NewManager(client *gotmux.Tmux, state *session.State, statePath string) *Manager {
@@ -62,6 +93,12 @@ func (m *Manager) reloadState() {
 		m.State.ViewportPaneID = ""
 	}
 
+	// Forget the docked terminal if its pane died
+	if m.State.DockedPaneID != "" && !paneExists(m.State.DockedPaneID) {
+		debugLog.Printf("reloadState: docked pane %s no longer exists, clearing", m.State.DockedPaneID)
+		m.State.DockedPaneID = ""
+	}
+
 	// Prune sessions whose tmux panes no longer exist
 	valid := m.State.Sessions[:0]
 	for _, s := range m.State.Sessions {
@@ -69,6 +106,7 @@ func (m *Manager) reloadState() {
 			valid = append(valid, s)
 		} else {
 			debugLog.Printf("reloadState: pruning session %s (%s), pane %s dead", s.ID, s.Name, s.TmuxPaneID)
+			m.State.Archive(s)
 		}
 	}
 	if len(valid) != len(m.State.Sessions) {
@@ -78,62 +116,77 @@ func (m *Manager) reloadState() {
 }
 
 // resolveViewportPane dynamically discovers the viewport pane by querying
-// tmux for actual panes in window 0. The sidebar pane is identified by its
-// StartCommand (contains "--sidebar") or by matching SidebarPaneID; the
-// other pane in window 0 is the viewport.
+// tmux for actual panes in window 0. When the viewport is split, this is
+// the pane in the active slot (the one SwitchTo targets).
 func (m *Manager) resolveViewportPane() (string, error) {
+	slots, err := m.resolveViewportSlots()
+	if err != nil {
+		return "", err
+	}
+	return slots[m.State.ActiveSlot], nil
+}
+
+// resolveViewportSlots discovers every viewport pane in window 0, in layout
+// order. The sidebar pane is identified by its StartCommand (contains
+// "--sidebar") or by matching SidebarPaneID; the preview split is ignored;
+// all remaining panes are viewport slots. Updates ViewportSlots, ActiveSlot
+// and ViewportPaneID to match.
+func (m *Manager) resolveViewportSlots() ([]string, error) {
 	sess, err := m.Client.GetSessionByName(SessionName())
 	if err != nil || sess == nil {
-		return "", fmt.Errorf("resolveViewportPane: failed to get %s session: %w", SessionName(), err)
+		return nil, fmt.Errorf("resolveViewportPane: failed to get %s session: %w", SessionName(), err)
 	}
 
 	win, err := sess.GetWindowByIndex(0)
 	if err != nil || win == nil {
-		return "", fmt.Errorf("resolveViewportPane: failed to get window 0: %w", err)
+		return nil, fmt.Errorf("resolveViewportPane: failed to get window 0: %w", err)
 	}
 
-	panes, err := win.ListPanes()
+	allPanes, err := win.ListPanes()
 	if err != nil {
-		return "", fmt.Errorf("resolveViewportPane: failed to list panes: %w", err)
+		return nil, fmt.Errorf("resolveViewportPane: failed to list panes: %w", err)
+	}
+
+	// The preview split and a docked paired terminal are never viewport candidates
+	var panes []*gotmux.Pane
+	for _, p := range allPanes {
+		if !m.isPreviewPane(p) && p.Id != m.State.DockedPaneID {
+			panes = append(panes, p)
+		}
+	}
+
+	var sidebarID string
+	for _, p := range panes {
+		if p.Id == m.State.SidebarPaneID || strings.Contains(p.StartCommand, "--sidebar") {
+			sidebarID = p.Id
+			break
+		}
 	}
 
 	if len(panes) < 2 {
 		// Viewport pane was destroyed (e.g. Ctrl+D). Identify the sidebar
 		// from the remaining pane(s) and repair the layout.
-		var sidebarID string
-		for _, p := range panes {
-			if p.Id == m.State.SidebarPaneID || strings.Contains(p.StartCommand, "--sidebar") {
-				sidebarID = p.Id
-				break
-			}
-		}
 		if sidebarID == "" {
-			return "", fmt.Errorf("resolveViewportPane: expected >= 2 panes in window 0, got %d, and could not identify sidebar", len(panes))
+			return nil, fmt.Errorf("resolveViewportPane: expected >= 2 panes in window 0, got %d, and could not identify sidebar", len(panes))
 		}
 
 		debugLog.Printf("resolveViewportPane: only %d pane(s) in window 0, repairing layout", len(panes))
 		newViewport, err := RepairLayout(sidebarID)
 		if err != nil {
-			return "", fmt.Errorf("resolveViewportPane: repair failed: %w", err)
+			return nil, fmt.Errorf("resolveViewportPane: repair failed: %w", err)
 		}
 
 		m.State.SidebarPaneID = sidebarID
 		m.State.ViewportPaneID = newViewport
+		m.State.ViewportSlots = nil
+		m.State.ActiveSlot = 0
 		m.State.Save(m.StatePath)
 		debugLog.Printf("resolveViewportPane: repaired, new viewport=%s", newViewport)
-		return newViewport, nil
-	}
-
-	var sidebarID string
-	for _, p := range panes {
-		if p.Id == m.State.SidebarPaneID || strings.Contains(p.StartCommand, "--sidebar") {
-			sidebarID = p.Id
-			break
-		}
+		return []string{newViewport}, nil
 	}
 
 	if sidebarID == "" {
-		return "", fmt.Errorf("resolveViewportPane: could not identify sidebar pane among %d panes", len(panes))
+		return nil, fmt.Errorf("resolveViewportPane: could not identify sidebar pane among %d panes", len(panes))
 	}
 
 	// Correct SidebarPaneID if it was wrong
@@ -142,25 +195,43 @@ func (m *Manager) resolveViewportPane() (string, error) {
 		m.State.SidebarPaneID = sidebarID
 	}
 
-	// The viewport is the non-sidebar pane in window 0
+	// Every non-sidebar pane in window 0 is a viewport slot
+	var slots []string
 	for _, p := range panes {
 		if p.Id != sidebarID {
-			if m.State.ViewportPaneID != p.Id {
-				debugLog.Printf("resolveViewportPane: correcting ViewportPaneID from %s to %s", m.State.ViewportPaneID, p.Id)
-				m.State.ViewportPaneID = p.Id
-			}
-			return p.Id, nil
+			slots = append(slots, p.Id)
 		}
 	}
+	m.setViewportSlots(slots)
+	return slots, nil
+}
 
-	return "", fmt.Errorf("resolveViewportPane: no non-sidebar pane found in window 0")
+// setViewportSlots records the live slot panes, clamping ActiveSlot and
+// pointing ViewportPaneID at the active slot.
+func (m *Manager) setViewportSlots(slots []string) {
+	if len(slots) > 1 {
+		m.State.ViewportSlots = slots
+	} else {
+		m.State.ViewportSlots = nil
+	}
+	if m.State.ActiveSlot >= len(slots) {
+		m.State.ActiveSlot = len(slots) - 1
+	}
+	if m.State.ActiveSlot < 0 {
+		m.State.ActiveSlot = 0
+	}
+	active := slots[m.State.ActiveSlot]
+	if m.State.ViewportPaneID != active {
+		debugLog.Printf("resolveViewportPane: correcting ViewportPaneID from %s to %s", m.State.ViewportPaneID, active)
+		m.State.ViewportPaneID = active
+	}
 }
 
 func (m *Manager) CreateSession(dir, name string) (*session.Session, error) {
 	m.reloadState()
 
 	project := session.DetectProject(dir)
-	windowName := fmt.Sprintf("%s/%s", project, name)
+	windowName := windowLabel(project, name)
 
 	debugLog.Printf("CreateSession: name=%s dir=%s window=%s", name, dir, windowName)
 
@@ -169,7 +240,7 @@ func (m *Manager) CreateSession(dir, name string) (*session.Session, error) {
 		"-t", SessionName(),
 		"-n", windowName,
 		"-c", dir,
-		"claude",
+		claudeCommand(),
 	); err != nil {
 		debugLog.Printf("CreateSession: new-window failed: %v", err)
 		return nil, fmt.Errorf("failed to create tmux window: %w", err)
@@ -206,6 +277,9 @@ func (m *Manager) CreateSession(dir, name string) (*session.Session, error) {
 		CreatedAt:  time.Now(),
 		Status:     session.StatusRunning,
 	}
+	if m.LogOutput {
+		newSession.Logging = m.startLogging(newSession.TmuxPaneID, newSession.ID) == nil
+	}
 
 	debugLog.Printf("CreateSession: created session %s pane=%s", newSession.ID, newSession.TmuxPaneID)
 
@@ -230,15 +304,18 @@ func (m *Manager) CreateWorktreeSession(repoRoot, branch string) (*session.Sessi
 	}
 
 	project := session.DetectProject(repoRoot)
-	windowName := fmt.Sprintf("%s/%s", project, branch)
+	windowName := windowLabel(project, branch)
 
-	if err := TmuxRun(
+	// A PORT of its own, so the worktree's dev server doesn't collide with
+	// the main checkout's
+	port := m.assignPort()
+	args := append([]string{
 		"new-window", "-d",
 		"-t", SessionName(),
 		"-n", windowName,
 		"-c", wtDir,
-		"claude",
-	); err != nil {
+	}, portEnv(port)...)
+	if err := TmuxRun(append(args, claudeCommand())...); err != nil {
 		debugLog.Printf("CreateWorktreeSession: new-window failed: %v, rolling back worktree", err)
 		worktree.Remove(repoRoot, wtDir)
 		return nil, fmt.Errorf("failed to create tmux window: %w", err)
@@ -279,9 +356,13 @@ func (m *Manager) CreateWorktreeSession(repoRoot, branch string) (*session.Sessi
 		Status:         session.StatusRunning,
 		IsWorktree:     true,
 		WorktreeBranch: branch,
+		Port:           port,
+	}
+	if m.LogOutput {
+		newSession.Logging = m.startLogging(newSession.TmuxPaneID, newSession.ID) == nil
 	}
 
-	debugLog.Printf("CreateWorktreeSession: created session %s pane=%s worktree=%s", newSession.ID, newSession.TmuxPaneID, wtDir)
+	debugLog.Printf("CreateWorktreeSession: created session %s pane=%s worktree=%s port=%d", newSession.ID, newSession.TmuxPaneID, wtDir, port)
 
 	m.State.AddSession(newSession)
 	m.SwitchTo(newSession.ID)
@@ -293,23 +374,108 @@ func (m *Manager) CreateWorktreeSession(repoRoot, branch string) (*session.Sessi
 func (m *Manager) CreateTerminal(dir, project string) (*session.Session, error) {
 	m.reloadState()
 
+	newSession, err := m.newTerminal(dir, project, "")
+	if err != nil {
+		return nil, err
+	}
+	m.SwitchTo(newSession.ID)
+	m.State.Save(m.StatePath)
+	return newSession, nil
+}
+
+// StartServices starts the services declared in the project directory's
+// manifest (.herd.json or Procfile) that aren't already running, each in
+// its own terminal under `herd service-run`, which restarts it when it
+// crashes. Returns how many were started.
+func (m *Manager) StartServices(project, dir string) (int, error) {
+	m.reloadState()
+
+	manifest, err := services.Load(dir)
+	if err != nil {
+		return 0, err
+	}
+	selfBin, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	started := 0
+	for _, svc := range manifest.Services {
+		sess := m.serviceTerminal(project, svc.Name)
+		if sess != nil && sess.Status != session.StatusShell {
+			continue // already running
+		}
+		// A stopped service is restarted in its old terminal
+		if sess == nil {
+			if sess, err = m.newTerminal(dir, project, ""); err != nil {
+				m.State.Save(m.StatePath)
+				return started, err
+			}
+		}
+		sess.Service = &session.ServiceInfo{Name: svc.Name, Port: svc.Port, ReadyCmd: svc.ReadyCmd}
+		line := shellquote.Join(selfBin, "service-run", svc.Name)
+		sess.Command = line
+		if err := TmuxRun("send-keys", "-t", sess.TmuxPaneID, line, "Enter"); err != nil {
+			m.State.Save(m.StatePath)
+			return started, fmt.Errorf("failed to start %s: %w", svc.Name, err)
+		}
+		// Until the next refresh sees it, so it isn't started twice
+		sess.SetStatus(session.StatusRunning, time.Now())
+		debugLog.Printf("StartServices: started %s in pane %s", svc.Name, sess.TmuxPaneID)
+		started++
+	}
+	m.State.Save(m.StatePath)
+	return started, nil
+}
+
+// serviceTerminal returns the live terminal running (or last running) the
+// named service of project.
+func (m *Manager) serviceTerminal(project, name string) *session.Session {
+	for i := range m.State.Sessions {
+		s := &m.State.Sessions[i]
+		if s.Project == project && s.Service != nil && s.Service.Name == name && s.Status != session.StatusExited {
+			return s
+		}
+	}
+	return nil
+}
+
+// windowLabel names the tmux window a session's pane has while it isn't
+// shown in the viewport.
+func windowLabel(project, name string) string {
+	return project + "/" + name
+}
+
+// sessionWindowLabel is windowLabel for an existing session. Terminals are
+// all called "shell", so they're told apart by ID.
+func sessionWindowLabel(s *session.Session) string {
+	if s.Type == session.TypeTerminal {
+		return windowLabel(s.Project, "term-"+s.ID[:8])
+	}
+	return windowLabel(s.Project, s.Name)
+}
+
+// newTerminal opens a $SHELL window and records it in state without
+// switching to it. parentID pairs the terminal with a Claude session.
+func (m *Manager) newTerminal(dir, project, parentID string) (*session.Session, error) {
 	shell := os.Getenv("SHELL")
 	if shell == "" {
 		shell = "/bin/sh"
 	}
 
 	id := uuid.New().String()
-	windowName := fmt.Sprintf("%s/term-%s", project, id[:8])
+	windowName := windowLabel(project, "term-"+id[:8])
 
 	debugLog.Printf("CreateTerminal: dir=%s project=%s window=%s", dir, project, windowName)
 
-	if err := TmuxRun(
+	port := m.sessionPort(dir, parentID)
+	args := append([]string{
 		"new-window", "-d",
 		"-t", SessionName(),
 		"-n", windowName,
 		"-c", dir,
-		shell,
-	); err != nil {
+	}, portEnv(port)...)
+	if err := TmuxRun(append(args, shell)...); err != nil {
 		debugLog.Printf("CreateTerminal: new-window failed: %v", err)
 		return nil, fmt.Errorf("failed to create tmux window: %w", err)
 	}
@@ -344,17 +510,26 @@ func (m *Manager) CreateTerminal(dir, project string) (*session.Session, error)
 		CreatedAt:  time.Now(),
 		Status:     session.StatusShell,
 		Type:       session.TypeTerminal,
+		ParentID:   parentID,
+		Port:       port,
+	}
+	if m.LogOutput {
+		newSession.Logging = m.startLogging(newSession.TmuxPaneID, newSession.ID) == nil
 	}
 
-	debugLog.Printf("CreateTerminal: created session %s pane=%s", newSession.ID, newSession.TmuxPaneID)
+	debugLog.Printf("CreateTerminal: created session %s pane=%s parent=%s", newSession.ID, newSession.TmuxPaneID, parentID)
 
 	m.State.AddSession(newSession)
-	m.SwitchTo(newSession.ID)
-	m.State.Save(m.StatePath)
-	return &newSession, nil
+	return m.State.FindByID(newSession.ID), nil
 }
 
 func (m *Manager) SwitchTo(sessionID string) error {
+	return m.switchTo(sessionID, "")
+}
+
+// switchTo implements SwitchTo. preferDock selects which paired terminal to
+// dock below the session, for when a paired terminal itself was selected.
+func (m *Manager) switchTo(sessionID, preferDock string) error {
 	m.reloadState()
 
 	sess := m.State.FindByID(sessionID)
@@ -383,14 +558,39 @@ func (m *Manager) SwitchTo(sessionID string) error {
 		return fmt.Errorf("session pane %s is the sidebar pane, refusing swap", sess.TmuxPaneID)
 	}
 
-	// Already in viewport — just focus it
-	if sess.TmuxPaneID == viewportPaneID {
+	// Paired terminal already docked below its parent — just focus it
+	if sess.TmuxPaneID == m.State.DockedPaneID {
+		debugLog.Printf("SwitchTo: pane %s is docked, focusing", sess.TmuxPaneID)
+		TmuxRun("select-pane", "-t", sess.TmuxPaneID)
+		return nil
+	}
+
+	// Paired terminals are shown docked below their parent
+	if parent := m.State.FindByID(sess.ParentID); parent != nil {
+		debugLog.Printf("SwitchTo: pane %s is paired with %s, switching to parent", sess.TmuxPaneID, parent.ID)
+		m.State.PairedHidden = false
+		paneID := sess.TmuxPaneID
+		if err := m.switchTo(parent.ID, sess.ID); err != nil {
+			return err
+		}
+		TmuxRun("select-pane", "-t", paneID)
+		return nil
+	}
+
+	// Already in a viewport slot — make that slot active and focus it
+	if idx := slotIndex(m.State.ViewportSlots, sess.TmuxPaneID); sess.TmuxPaneID == viewportPaneID || idx >= 0 {
 		debugLog.Printf("SwitchTo: pane %s already in viewport, focusing", sess.TmuxPaneID)
+		if idx >= 0 {
+			m.State.ActiveSlot = idx
+		}
+		m.State.ViewportPaneID = sess.TmuxPaneID
 		m.State.LastActiveSession = sessionID
+		m.State.RecordVisit(sessionID)
 		if sess.Status == session.StatusDone || sess.Status == session.StatusPlanReady {
-			sess.Status = session.StatusIdle
+			sess.SetStatus(session.StatusIdle, time.Now())
 		}
 		PlaceholderGuardOff()
+		m.dock(preferDock)
 		TmuxRun("select-pane", "-t", sess.TmuxPaneID)
 		m.State.Save(m.StatePath)
 		return nil
@@ -407,11 +607,17 @@ func (m *Manager) SwitchTo(sessionID string) error {
 	// and the old viewport pane moved to where the session pane was.
 	PlaceholderGuardOff()
 	m.State.ViewportPaneID = sess.TmuxPaneID
+	if len(m.State.ViewportSlots) > m.State.ActiveSlot {
+		m.State.ViewportSlots[m.State.ActiveSlot] = sess.TmuxPaneID
+	}
 	m.State.LastActiveSession = sessionID
+	m.State.RecordVisit(sessionID)
 	if sess.Status == session.StatusDone || sess.Status == session.StatusPlanReady {
-		sess.Status = session.StatusIdle
+		sess.SetStatus(session.StatusIdle, time.Now())
 	}
 
+	m.dock(preferDock)
+
 	// Focus the viewport pane so keyboard input goes to the session
 	if err := TmuxRun("select-pane", "-t", sess.TmuxPaneID); err != nil {
 		debugLog.Printf("SwitchTo: select-pane failed: %v", err)
@@ -434,6 +640,10 @@ func (m *Manager) KillSession(sessionID string) error {
 
 	debugLog.Printf("KillSession: session=%s pane=%s viewportPane=%s", sessionID, sess.TmuxPaneID, m.State.ViewportPaneID)
 
+	// Return any docked terminal to its own window so window 0 only holds
+	// the sidebar and viewport slots while panes are shuffled.
+	m.undock()
+
 	isInViewport := sess.TmuxPaneID == m.State.ViewportPaneID
 	paneID := sess.TmuxPaneID
 	isWorktree := sess.IsWorktree
@@ -441,6 +651,14 @@ func (m *Manager) KillSession(sessionID string) error {
 
 	// Remove from state first
 	m.State.RemoveSession(sessionID)
+	if m.LogDir != "" {
+		outlog.Remove(m.LogDir, sessionID)
+	}
+
+	// Paired terminals outlive their parent as regular terminals
+	for _, t := range m.State.PairedTerminals(sessionID) {
+		t.ParentID = ""
+	}
 
 	// Check if another session still references this pane
 	otherUsesPane := false
@@ -453,11 +671,30 @@ func (m *Manager) KillSession(sessionID string) error {
 	}
 
 	if otherUsesPane {
+		m.syncDock()
 		m.State.Save(m.StatePath)
 		return nil
 	}
 
-	if isInViewport && len(m.State.Sessions) > 0 {
+	if slotIndex(m.State.ViewportSlots, paneID) >= 0 {
+		// Viewport is split — killing the pane closes its slot, and the
+		// remaining slots keep their sessions.
+		wasActive := paneID == m.State.ViewportPaneID
+		if err := TmuxRun("kill-pane", "-t", paneID); err != nil {
+			debugLog.Printf("KillSession: kill-pane %s failed: %v", paneID, err)
+		} else {
+			debugLog.Printf("KillSession: killed pane %s, closing its slot", paneID)
+		}
+		if wasActive {
+			if _, err := m.resolveViewportSlots(); err == nil {
+				if next := m.State.FindByPaneID(m.State.ViewportPaneID); next != nil {
+					m.State.LastActiveSession = next.ID
+				} else {
+					m.State.LastActiveSession = ""
+				}
+			}
+		}
+	} else if isInViewport && len(m.State.Sessions) > 0 {
 		// Swap a replacement session into the viewport BEFORE killing,
 		// so window 0 always keeps two panes (sidebar + viewport).
 		replacement := &m.State.Sessions[0]
@@ -505,6 +742,7 @@ func (m *Manager) KillSession(sessionID string) error {
 		}
 	}
 
+	m.syncDock()
 	m.State.Save(m.StatePath)
 	return nil
 }
@@ -533,6 +771,24 @@ func (m *Manager) MoveProject(project string, direction int) bool {
 	return false
 }
 
+// SetTags replaces a session's tags.
+func (m *Manager) SetTags(sessionID string, tags []string) error {
+	m.reloadState()
+	sess := m.State.FindByID(sessionID)
+	if sess == nil {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	sess.Tags = tags
+	return m.State.Save(m.StatePath)
+}
+
+// SetSidebarView persists the sidebar grouping mode for this profile.
+func (m *Manager) SetSidebarView(view string) {
+	m.reloadState()
+	m.State.SidebarView = view
+	m.State.Save(m.StatePath)
+}
+
 // CollectLivePanes queries tmux for all panes in herd-main, returning them
 // as session.LivePane values with pre-cleaned titles.
 func (m *Manager) CollectLivePanes() ([]session.LivePane, error) {
@@ -589,11 +845,16 @@ func (m *Manager) Reconcile() bool {
 	if m.State.SidebarPaneID != "" {
 		layoutPaneIDs[m.State.SidebarPaneID] = true
 	}
-	// Exclude the viewport pane only when it's NOT a session pane
-	// (i.e., it's the welcome placeholder)
+	// Exclude viewport panes only when they're NOT session panes
+	// (i.e., the welcome placeholder or an empty split slot)
 	if m.State.ViewportPaneID != "" && m.State.FindByPaneID(m.State.ViewportPaneID) == nil {
 		layoutPaneIDs[m.State.ViewportPaneID] = true
 	}
+	for _, id := range m.State.ViewportSlots {
+		if m.State.FindByPaneID(id) == nil {
+			layoutPaneIDs[id] = true
+		}
+	}
 
 	changed := m.State.Reconcile(livePanes, layoutPaneIDs)
 
@@ -626,8 +887,10 @@ func (m *Manager) RefreshStatus() bool {
 			changed = m.refreshTerminalStatus(s) || changed
 		} else {
 			changed = m.refreshClaudeStatus(s) || changed
+			m.escalate(s)
 		}
 	}
+	changed = m.detectPortConflicts() || changed
 	if !m.notifyReady {
 		m.notifyReady = true
 	}
@@ -637,6 +900,205 @@ func (m *Manager) RefreshStatus() bool {
 	return changed
 }
 
+// sendNotification fills in the session fields of e and hands it to the
+// Notifier, unless the rules suppress it.
+func (m *Manager) sendNotification(s *session.Session, e notify.Event) {
+	if m.Notifier == nil {
+		return
+	}
+	e.SessionID = s.ID
+	e.SessionName = s.DisplayName()
+	e.ProjectName = s.Project
+
+	now := time.Now()
+	if !m.Rules.Allow(e, now) {
+		debugLog.Printf("notify: %s (%s) suppressed by rules", s.ID, e.Status)
+		return
+	}
+	if m.Rules.SkipVisible && m.State.InViewport(s.TmuxPaneID) && clientFocused() {
+		debugLog.Printf("notify: %s (%s) suppressed, session is visible", s.ID, e.Status)
+		return
+	}
+	if e.Kind == notify.KindStatus && e.Status == session.StatusInput {
+		if m.lastNotified == nil {
+			m.lastNotified = make(map[string]time.Time)
+		}
+		m.lastNotified[s.ID] = now
+	}
+	m.Notifier.Notify(e)
+}
+
+// escalate repeats the "needs input" notification for a session that has
+// been waiting longer than Rules.EscalateAfter since it was last notified.
+func (m *Manager) escalate(s *session.Session) {
+	if s.Status != session.StatusInput {
+		delete(m.lastNotified, s.ID)
+		return
+	}
+	if m.Rules.EscalateAfter <= 0 || !m.notifyReady || m.State.InViewport(s.TmuxPaneID) {
+		return
+	}
+	last, ok := m.lastNotified[s.ID]
+	if !ok {
+		last = s.StatusSince()
+	}
+	if time.Since(last) < time.Duration(m.Rules.EscalateAfter)*time.Minute {
+		return
+	}
+	m.sendNotification(s, notify.Event{Status: session.StatusInput, Escalated: true})
+	if _, ok := m.lastNotified[s.ID]; !ok {
+		// Suppressed: don't re-check on every tick
+		if m.lastNotified == nil {
+			m.lastNotified = make(map[string]time.Time)
+		}
+		m.lastNotified[s.ID] = time.Now()
+	}
+}
+
+// ToggleSessionMute mutes or unmutes notifications for one session and
+// returns whether it is now muted.
+func (m *Manager) ToggleSessionMute(sessionID string) (bool, error) {
+	muted := m.Rules.ToggleSession(sessionID)
+	return muted, m.saveRules()
+}
+
+// ToggleProjectMute mutes or unmutes notifications for a whole project and
+// returns whether it is now muted.
+func (m *Manager) ToggleProjectMute(project string) (bool, error) {
+	muted := m.Rules.ToggleProject(project)
+	return muted, m.saveRules()
+}
+
+// ToggleRule switches a notification rule on or off and returns whether it
+// is now on.
+func (m *Manager) ToggleRule(rule notify.Rule) (bool, error) {
+	on, err := m.Rules.Toggle(rule)
+	if err != nil {
+		return on, err
+	}
+	return on, m.saveRules()
+}
+
+func (m *Manager) saveRules() error {
+	if m.SaveRules == nil {
+		return nil
+	}
+	return m.SaveRules(m.Rules)
+}
+
+// clientFocused reports whether any attached client's terminal has focus.
+// Needs focus-events, which SetupLayout turns on.
+func clientFocused() bool {
+	out, err := TmuxRunOutput("list-clients", "-F", "#{client_flags}")
+	if err != nil {
+		return false
+	}
+	for _, flags := range strings.Split(strings.TrimSpace(out), "\n") {
+		for _, f := range strings.Split(flags, ",") {
+			if f == "focused" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UsageReport is what a transcript scan found, computed by ScanUsage off
+// the UI loop and applied with ApplyUsage.
+type UsageReport struct {
+	Claimed map[string][]string    // session ID → transcripts newly attributed to it
+	Usage   map[string]usage.Usage // session ID → token usage and cost
+	Context map[string]int         // session ID → context percent used
+}
+
+// UsageSnapshot copies the Claude sessions for ScanUsage, so the scan can
+// run while the UI keeps changing the live ones.
+func (m *Manager) UsageSnapshot() []session.Session {
+	var out []session.Session
+	for _, s := range m.State.Sessions {
+		if s.Type == session.TypeClaude {
+			s.Transcripts = slices.Clone(s.Transcripts)
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ScanUsage attributes new Claude transcripts to the given sessions and
+// totals their usage. It reads and parses transcripts, so it runs in a
+// goroutine on a UsageSnapshot; it doesn't touch the manager's state.
+func (m *Manager) ScanUsage(sessions []session.Session) UsageReport {
+	r := UsageReport{
+		Claimed: make(map[string][]string),
+		Usage:   make(map[string]usage.Usage),
+		Context: make(map[string]int),
+	}
+	if m.Usage == nil {
+		return r
+	}
+	before := make([]int, len(sessions))
+	for i := range sessions {
+		before[i] = len(sessions[i].Transcripts)
+	}
+	m.Usage.Claim(sessions)
+	for i := range sessions {
+		s := &sessions[i]
+		if len(s.Transcripts) > before[i] {
+			r.Claimed[s.ID] = s.Transcripts[before[i]:]
+		}
+		r.Usage[s.ID] = m.Usage.SessionUsage(s)
+		if tr, ok := m.Usage.Latest(s); ok {
+			r.Context[s.ID] = tr.ContextPercent(m.ContextWindow)
+		}
+	}
+	return r
+}
+
+// ApplyUsage records a scan's results: new transcripts and context usage
+// go into state, warning once when a session crosses ContextWarnPercent,
+// and the totals are kept for CachedUsage. Returns true if any session
+// changed.
+func (m *Manager) ApplyUsage(r UsageReport) bool {
+	m.usageCache = r.Usage
+	changed := false
+	for i := range m.State.Sessions {
+		s := &m.State.Sessions[i]
+		if s.Type != session.TypeClaude {
+			continue
+		}
+		for _, id := range r.Claimed[s.ID] {
+			if !slices.Contains(s.Transcripts, id) {
+				s.Transcripts = append(s.Transcripts, id)
+				changed = true
+			}
+		}
+		pct := r.Context[s.ID]
+		if pct == s.ContextPercent {
+			continue
+		}
+		warn := m.ContextWarnPercent
+		if warn > 0 && s.ContextPercent < warn && pct >= warn {
+			m.sendNotification(s, notify.Event{
+				Kind:           notify.KindContext,
+				Status:         s.Status,
+				ContextPercent: pct,
+			})
+		}
+		s.ContextPercent = pct
+		changed = true
+	}
+	if changed {
+		m.State.Save(m.StatePath)
+	}
+	return changed
+}
+
+// CachedUsage returns a session's token usage and estimated cost as of the
+// last ApplyUsage. It never reads transcripts, so views can call it freely.
+func (m *Manager) CachedUsage(sessionID string) usage.Usage {
+	return m.usageCache[sessionID]
+}
+
 func (m *Manager) refreshClaudeStatus(s *session.Session) bool {
 	changed := false
 	raw := DetectStatus(s.TmuxPaneID)
@@ -645,10 +1107,10 @@ func (m *Manager) refreshClaudeStatus(s *session.Session) bool {
 	var next session.Status
 	switch {
 	// Running → Idle while not in viewport → mark done
-	case prev == session.StatusRunning && raw == session.StatusIdle && s.TmuxPaneID != m.State.ViewportPaneID:
+	case prev == session.StatusRunning && raw == session.StatusIdle && !m.State.InViewport(s.TmuxPaneID):
 		next = session.StatusDone
 	// Running → PlanReady while not in viewport → keep PlanReady
-	case prev == session.StatusRunning && raw == session.StatusPlanReady && s.TmuxPaneID != m.State.ViewportPaneID:
+	case prev == session.StatusRunning && raw == session.StatusPlanReady && !m.State.InViewport(s.TmuxPaneID):
 		next = session.StatusPlanReady
 	// Already done and still idle → keep done (don't let polling overwrite)
 	case prev == session.StatusDone && raw == session.StatusIdle:
@@ -669,29 +1131,17 @@ func (m *Manager) refreshClaudeStatus(s *session.Session) bool {
 
 	if s.Status != next {
 		// Fire notification on meaningful transitions (not during startup)
-		if m.notifyReady && m.Notifier != nil {
+		if m.notifyReady {
 			switch {
 			case prev == session.StatusRunning && next == session.StatusDone:
-				m.Notifier.Notify(notify.Event{
-					SessionName: s.DisplayName(),
-					ProjectName: s.Project,
-					Status:      session.StatusDone,
-				})
+				m.sendNotification(s, notify.Event{Status: session.StatusDone})
 			case prev == session.StatusRunning && next == session.StatusPlanReady:
-				m.Notifier.Notify(notify.Event{
-					SessionName: s.DisplayName(),
-					ProjectName: s.Project,
-					Status:      session.StatusPlanReady,
-				})
-			case prev != session.StatusInput && next == session.StatusInput && s.TmuxPaneID != m.State.ViewportPaneID:
-				m.Notifier.Notify(notify.Event{
-					SessionName: s.DisplayName(),
-					ProjectName: s.Project,
-					Status:      session.StatusInput,
-				})
+				m.sendNotification(s, notify.Event{Status: session.StatusPlanReady})
+			case prev != session.StatusInput && next == session.StatusInput && !m.State.InViewport(s.TmuxPaneID):
+				m.sendNotification(s, notify.Event{Status: session.StatusInput})
 			}
 		}
-		s.Status = next
+		s.SetStatus(next, time.Now())
 		changed = true
 	}
 
@@ -706,6 +1156,43 @@ func (m *Manager) refreshClaudeStatus(s *session.Session) bool {
 	return changed
 }
 
+// serviceReady reports whether a running service terminal passes its
+// readiness check: the expected port is listening, the check command
+// succeeds, or, with neither declared, it is simply running. A service
+// stays ready until its process stops. Check commands run in the
+// background; their last result is used.
+func (m *Manager) serviceReady(s *session.Session) bool {
+	if s.Service.Ready {
+		return true
+	}
+	switch {
+	case s.Service.Port > 0:
+		for _, l := range s.Listeners {
+			if l.Port == s.Service.Port {
+				return true
+			}
+		}
+		return false
+	case s.Service.ReadyCmd != "":
+		dir, command := s.Dir, s.Service.ReadyCmd
+		return m.ready.latest(s.ID, func() bool { return readyCheck(dir, command) })
+	}
+	return true
+}
+
+// readyTimeout bounds a single ready_cmd run.
+const readyTimeout = 5 * time.Second
+
+// readyCheck runs a service's ready_cmd in dir and reports whether it
+// exited 0 within readyTimeout.
+func readyCheck(dir, command string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
 func (m *Manager) refreshTerminalStatus(s *session.Session) bool {
 	changed := false
 
@@ -713,24 +1200,64 @@ func (m *Manager) refreshTerminalStatus(s *session.Session) bool {
 
 	// If a command is running, check for listening ports
 	if status == session.StatusRunning {
-		port := DetectListeningPort(s.TmuxPaneID)
+		listeners := DetectListeners(s.TmuxPaneID)
+		port := ServicePort(listeners)
 		if port > 0 {
 			status = session.StatusService
-			if s.ServicePort != port {
-				s.ServicePort = port
+		}
+		if s.ServicePort != port {
+			s.ServicePort = port
+			changed = true
+		}
+		if !slices.Equal(s.Listeners, listeners) {
+			s.Listeners = listeners
+			changed = true
+		}
+		// Remember the command line, so it can be rerun after it stops.
+		// Services keep the command they were started with.
+		if s.Service == nil {
+			paneID := s.TmuxPaneID
+			if command := m.jobs.latest(s.ID, func() string { return jobCommand(paneID) }); command != "" && command != s.Command {
+				s.Command = command
 				changed = true
 			}
-		} else if s.ServicePort != 0 {
+		}
+	} else {
+		m.jobs.forget(s.ID)
+		if s.ServicePort != 0 || s.Listeners != nil {
 			s.ServicePort = 0
+			s.Listeners = nil
 			changed = true
 		}
-	} else if s.ServicePort != 0 {
-		s.ServicePort = 0
+	}
+
+	if !s.RestartingAt.IsZero() && restartSettled(s, status) {
+		s.RestartingAt = time.Time{}
+		s.RestartPort = 0
 		changed = true
 	}
 
+	// Project services show as listening only once their readiness check
+	// passes
+	if s.Service != nil {
+		stopped := status == session.StatusShell || status == session.StatusExited
+		if stopped {
+			m.ready.forget(s.ID)
+		}
+		ready := !stopped && m.serviceReady(s)
+		if s.Service.Ready != ready {
+			s.Service.Ready = ready
+			changed = true
+		}
+		if ready {
+			status = session.StatusService
+		} else if status == session.StatusService {
+			status = session.StatusRunning
+		}
+	}
+
 	if s.Status != status {
-		s.Status = status
+		s.SetStatus(status, time.Now())
 		changed = true
 	}
 
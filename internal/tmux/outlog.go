@@ -0,0 +1,185 @@
+package tmux
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/allenan/herd/internal/outlog"
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/shellquote"
+)
+
+// startLogging pipes a pane's output into its session's log through
+// `herd log-write`.
+func (m *Manager) startLogging(paneID, sessionID string) error {
+	if m.LogDir == "" {
+		return fmt.Errorf("output logging is not set up")
+	}
+	selfBin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	line := "exec " + shellquote.Join(selfBin, "log-write", outlog.Path(m.LogDir, sessionID))
+	if err := TmuxRun("pipe-pane", "-t", paneID, line); err != nil {
+		return fmt.Errorf("failed to log output: %w", err)
+	}
+	return nil
+}
+
+// ToggleLogging starts or stops recording a session's output to its log.
+// Returns whether it is now recorded.
+func (m *Manager) ToggleLogging(sessionID string) (bool, error) {
+	m.reloadState()
+	s := m.State.FindByID(sessionID)
+	if s == nil {
+		return false, fmt.Errorf("session %s not found", sessionID)
+	}
+	if s.Logging {
+		// pipe-pane without a command closes the pipe
+		if err := TmuxRun("pipe-pane", "-t", s.TmuxPaneID); err != nil {
+			return true, fmt.Errorf("failed to stop logging: %w", err)
+		}
+	} else if err := m.startLogging(s.TmuxPaneID, s.ID); err != nil {
+		return false, err
+	}
+	s.Logging = !s.Logging
+	debugLog.Printf("ToggleLogging: session %s logging=%v", s.ID, s.Logging)
+	m.State.Save(m.StatePath)
+	return s.Logging, nil
+}
+
+// Match is a line of session output matching a search.
+type Match struct {
+	SessionID string `json:"session_id"`
+	Session   string `json:"session"` // display name
+	Project   string `json:"project"`
+	Source    string `json:"source"` // "log" or "scrollback"
+	Text      string `json:"text"`   // the whole line
+	Hit       string `json:"hit"`    // the matching part
+	Line      int    `json:"line"`   // scrollback matches: line number from the top of the scrollback
+}
+
+// Grep searches the output logs and current scrollback of the live
+// sessions for re. Each session's matches come oldest first, log before
+// scrollback; the tail of the log that is still in the scrollback is only
+// searched there.
+func Grep(sessions []session.Session, logDir string, re *regexp.Regexp) []Match {
+	var matches []Match
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Status == session.StatusExited {
+			continue
+		}
+		match := func(source, line string, n int) (Match, bool) {
+			line = strings.TrimRight(line, " ")
+			hit := re.FindString(line)
+			if hit == "" {
+				return Match{}, false
+			}
+			return Match{
+				SessionID: s.ID,
+				Session:   s.DisplayName(),
+				Project:   s.Project,
+				Source:    source,
+				Text:      line,
+				Hit:       hit,
+				Line:      n,
+			}, true
+		}
+
+		scrollback := captureScrollback(s.TmuxPaneID)
+		if logDir != "" {
+			log := readLog(logDir, s.ID)
+			for n, line := range log[:outlog.Overlap(log, scrollback)] {
+				if m, ok := match("log", line, n); ok {
+					matches = append(matches, m)
+				}
+			}
+		}
+		for n, line := range scrollback {
+			if m, ok := match("scrollback", line, n); ok {
+				matches = append(matches, m)
+			}
+		}
+	}
+	return matches
+}
+
+// captureScrollback returns a pane's whole scrollback, wrapped lines
+// joined.
+func captureScrollback(paneID string) []string {
+	content, err := TmuxRunOutput("capture-pane", "-p", "-J", "-S", "-", "-t", paneID)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(content, "\n"), "\n")
+}
+
+// readLog returns the lines of a session's output logs, oldest first.
+func readLog(logDir, sessionID string) []string {
+	var lines []string
+	for _, path := range outlog.Files(logDir, sessionID) {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		f.Close()
+	}
+	return lines
+}
+
+// JumpToMatch puts a session's pane in copy mode on a scrollback match.
+// The scrollback is captured again, and the copy of the match's line
+// closest to where it was found is searched for, counting the copies
+// below it, so repeated lines land on the right one even after more
+// output arrived. Matches only in the log can't be reached.
+func (m *Manager) JumpToMatch(sessionID string, match Match) error {
+	s := m.State.FindByID(sessionID)
+	if s == nil {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	if match.Source != "scrollback" {
+		return fmt.Errorf("match has scrolled out of the pane, it's only in the output log")
+	}
+	lines := captureScrollback(s.TmuxPaneID)
+	target := -1
+	for n, line := range lines {
+		if strings.TrimRight(line, " ") == match.Text && (target < 0 || abs(n-match.Line) < abs(target-match.Line)) {
+			target = n
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("match is no longer in the scrollback")
+	}
+	count := 0
+	for _, line := range lines[target:] {
+		count += strings.Count(line, match.Text)
+	}
+
+	if err := TmuxRun("copy-mode", "-t", s.TmuxPaneID); err != nil {
+		return fmt.Errorf("failed to enter copy mode: %w", err)
+	}
+	// search-backward-text is a literal search (tmux 3.2+); older
+	// versions only have the pattern search
+	n := strconv.Itoa(count)
+	if err := TmuxRun("send-keys", "-t", s.TmuxPaneID, "-X", "-N", n, "search-backward-text", match.Text); err != nil {
+		return TmuxRun("send-keys", "-t", s.TmuxPaneID, "-X", "-N", n, "search-backward", match.Text)
+	}
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
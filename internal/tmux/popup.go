@@ -73,3 +73,9 @@ func ShowPopup(opts PopupOpts, command ...string) error {
 	args = append(args, command...)
 	return TmuxRun(args...)
 }
+
+// CopyToClipboard puts text in a tmux paste buffer and, with -w, on the
+// attached client's clipboard (OSC 52), which also works over SSH.
+func CopyToClipboard(text string) error {
+	return TmuxRun("set-buffer", "-w", text)
+}
@@ -0,0 +1,303 @@
+package tmux
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// inUsePattern matches the errors servers print when their port is taken:
+// Node's EADDRINUSE, bind(2)'s "address already in use" (Go, Rails,
+// Python) and Vite's "Port 5173 is in use".
+var inUsePattern = regexp.MustCompile(`(?i)EADDRINUSE|address already in use|port \d+ is (already )?in use`)
+
+// inUsePortPattern finds the port in such an error: "port 3000" or the
+// last ":3000" of an address.
+var inUsePortPattern = regexp.MustCompile(`(?i)port\D{0,4}(\d{2,5})|:(\d{2,5})\b`)
+
+// clockPattern matches times of day like 12:30 or 12:30:45.123 that log
+// prefixes put on the error line; their minutes would read as ports.
+var clockPattern = regexp.MustCompile(`\d{1,2}(?::\d{2}){1,2}(?:[.,]\d+)?`)
+
+// conflictScanLines is how many lines from the bottom of a terminal are
+// searched for an "in use" error.
+const conflictScanLines = 15
+
+// maxRunLines bounds how far back into the scrollback the output of the
+// current command is captured.
+const maxRunLines = 500
+
+// paneScan is what the last conflict scan of a terminal saw. Panes are
+// only captured again when their command starts, changes or exits.
+type paneScan struct {
+	run      string // status and command when last scanned
+	runStart int    // absolute line the current command's output starts at, -1 = unknown
+	cursor   int    // absolute cursor line when last scanned, -1 = unknown
+	port     int    // port of the "in use" error in the current command's output
+}
+
+// failedPort returns the port of the last "address in use" error printed
+// by the terminal's current or just-exited command, or 0 if there is none.
+// Errors printed before the command started don't count, so starting it
+// again clears the conflict.
+func (m *Manager) failedPort(s *session.Session) int {
+	run := string(s.Status) + " " + s.Title
+	sc := m.scans[s.ID]
+	if sc != nil && sc.run == run {
+		return sc.port
+	}
+	if sc == nil {
+		if m.scans == nil {
+			m.scans = make(map[string]*paneScan)
+		}
+		sc = &paneScan{runStart: -1, cursor: -1}
+		m.scans[s.ID] = sc
+	}
+	if s.Status == session.StatusRunning {
+		// Output of the new command follows the prompt seen last time
+		sc.runStart = sc.cursor
+	}
+	sc.run = run
+	sc.port = 0
+
+	out, err := TmuxRunOutput("display-message", "-p", "-t", s.TmuxPaneID, "#{history_size} #{cursor_y}")
+	if err != nil {
+		return 0
+	}
+	var history, cursorY int
+	if _, err := fmt.Sscan(out, &history, &cursorY); err != nil {
+		return 0
+	}
+	sc.cursor = history + cursorY
+
+	args := []string{"capture-pane", "-p", "-t", s.TmuxPaneID}
+	if sc.runStart >= 0 {
+		args = append(args, "-S", strconv.Itoa(max(sc.runStart-history, -maxRunLines)))
+	}
+	content, err := TmuxRunOutput(args...)
+	if err != nil {
+		return 0
+	}
+	sc.port = inUsePort(strings.Split(strings.TrimRight(content, "\n"), "\n"))
+	return sc.port
+}
+
+// inUsePort returns the port of the last "address in use" error among the
+// bottom conflictScanLines non-empty lines, or 0 if there is none.
+func inUsePort(lines []string) int {
+	scanned := 0
+	for i := len(lines) - 1; i >= 0 && scanned < conflictScanLines; i-- {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		scanned++
+		if !inUsePattern.MatchString(line) {
+			continue
+		}
+		matches := inUsePortPattern.FindAllStringSubmatch(stripClock(line), -1)
+		for j := len(matches) - 1; j >= 0; j-- {
+			digits := matches[j][1] + matches[j][2]
+			if port, err := strconv.Atoi(digits); err == nil && port > 0 && port <= 65535 {
+				return port
+			}
+		}
+	}
+	return 0
+}
+
+// stripClock blanks out the times of day in line. A match that is part of
+// an address, like the 1:80 of 10.0.0.1:8080, is left alone.
+func stripClock(line string) string {
+	b := []byte(line)
+	for _, loc := range clockPattern.FindAllStringIndex(line, -1) {
+		if loc[0] > 0 && strings.IndexByte("0123456789.:", line[loc[0]-1]) >= 0 {
+			continue
+		}
+		if loc[1] < len(line) && strings.IndexByte("0123456789:", line[loc[1]]) >= 0 {
+			continue
+		}
+		for i := loc[0]; i < loc[1]; i++ {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// wantedPorts returns the ports a terminal expects to listen on but
+// doesn't: its service's port, the port it had before a restart, and the
+// port of an "address in use" error its command printed.
+func (m *Manager) wantedPorts(s *session.Session) []int {
+	var ports []int
+	if s.Service != nil && s.Service.Port > 0 {
+		ports = append(ports, s.Service.Port)
+	}
+	if s.RestartPort > 0 {
+		ports = append(ports, s.RestartPort)
+	}
+	if len(s.Listeners) == 0 {
+		if port := m.failedPort(s); port > 0 {
+			ports = append(ports, port)
+		}
+	}
+	return slices.DeleteFunc(ports, func(port int) bool {
+		return slices.Contains(s.Ports(), port)
+	})
+}
+
+// peerSessions are the sessions of the other profiles whose tmux server is
+// running, keyed by profile name. Their ports are as fresh as the last
+// refresh of that profile's sidebar. A stopped profile's state still lists
+// the sessions it had, which hold nothing any more.
+func (m *Manager) peerSessions() map[string][]session.Session {
+	peers := make(map[string][]session.Session, len(m.Peers))
+	for name, p := range m.Peers {
+		if !ServerRunningAt(p.SocketPath()) {
+			continue
+		}
+		path := p.StatePath()
+		state, err := session.LoadState(path)
+		if err != nil {
+			debugLog.Printf("peerSessions: failed to load %s: %v", path, err)
+			continue
+		}
+		peers[name] = state.Sessions
+	}
+	return peers
+}
+
+// portHolder finds a live session other than s listening on port, in this
+// profile first, then in the others.
+func (m *Manager) portHolder(s *session.Session, port int, peers func() map[string][]session.Session) *session.PortConflict {
+	holds := func(h *session.Session) bool {
+		return h.Status != session.StatusExited && slices.Contains(h.Ports(), port)
+	}
+	for i := range m.State.Sessions {
+		h := &m.State.Sessions[i]
+		if h.ID != s.ID && holds(h) {
+			return &session.PortConflict{Port: port, Holder: h.DisplayName()}
+		}
+	}
+	for name, sessions := range peers() {
+		for i := range sessions {
+			if h := &sessions[i]; holds(h) {
+				return &session.PortConflict{Port: port, Holder: h.DisplayName(), Profile: name}
+			}
+		}
+	}
+	return nil
+}
+
+// detectPortConflicts records on each terminal whether a port it wants is
+// held by another herd session. Peer profiles' state is only read when a
+// terminal is missing a port.
+func (m *Manager) detectPortConflicts() bool {
+	var peers map[string][]session.Session
+	loadPeers := func() map[string][]session.Session {
+		if peers == nil {
+			peers = m.peerSessions()
+		}
+		return peers
+	}
+
+	changed := false
+	for i := range m.State.Sessions {
+		s := &m.State.Sessions[i]
+		if s.Type != session.TypeTerminal {
+			continue
+		}
+		var conflict *session.PortConflict
+		if s.Status == session.StatusExited {
+			delete(m.scans, s.ID)
+		} else {
+			for _, port := range m.wantedPorts(s) {
+				if conflict = m.portHolder(s, port, loadPeers); conflict != nil {
+					break
+				}
+			}
+		}
+		if (conflict == nil) != (s.PortConflict == nil) || (conflict != nil && *conflict != *s.PortConflict) {
+			if conflict != nil {
+				debugLog.Printf("detectPortConflicts: pane %s wants %s", s.TmuxPaneID, conflict)
+			}
+			s.PortConflict = conflict
+			changed = true
+		}
+	}
+	return changed
+}
+
+// assignPort picks the PORT for a new worktree session: the lowest port
+// from PortBase up that no session in any profile was given or listens
+// on, and that is free on this machine. Returns 0 when ports aren't
+// assigned or none is free.
+func (m *Manager) assignPort() int {
+	if m.PortBase <= 0 {
+		return 0
+	}
+	taken := map[int]bool{}
+	mark := func(sessions []session.Session) {
+		for i := range sessions {
+			s := &sessions[i]
+			if s.Status == session.StatusExited {
+				continue
+			}
+			taken[s.Port] = true
+			for _, port := range s.Ports() {
+				taken[port] = true
+			}
+		}
+	}
+	mark(m.State.Sessions)
+	for _, sessions := range m.peerSessions() {
+		mark(sessions)
+	}
+
+	for port := m.PortBase; port <= 65535; port++ {
+		if !taken[port] && portFree(port) {
+			return port
+		}
+	}
+	return 0
+}
+
+// portFree reports whether nothing on this machine listens on port.
+func portFree(port int) bool {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+// sessionPort returns the PORT a new terminal inherits: its paired
+// session's, or that of the worktree session whose directory it opens in.
+func (m *Manager) sessionPort(dir, parentID string) int {
+	if parentID != "" {
+		if parent := m.State.FindByID(parentID); parent != nil {
+			return parent.Port
+		}
+	}
+	for i := range m.State.Sessions {
+		s := &m.State.Sessions[i]
+		if s.IsWorktree && s.Port > 0 && (dir == s.Dir || strings.HasPrefix(dir, s.Dir+"/")) {
+			return s.Port
+		}
+	}
+	return 0
+}
+
+// portEnv returns the tmux -e flag that sets PORT for a new pane, or
+// nothing when port is 0.
+func portEnv(port int) []string {
+	if port <= 0 {
+		return nil
+	}
+	return []string{"-e", fmt.Sprintf("PORT=%d", port)}
+}
@@ -0,0 +1,44 @@
+package tmux
+
+import "testing"
+
+func TestInUsePort(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  int
+	}{
+		{"node", []string{"Error: listen EADDRINUSE: address already in use :::3000"}, 3000},
+		{"go", []string{"listen tcp 0.0.0.0:4000: bind: address already in use"}, 4000},
+		{"rails", []string{`Address already in use - bind(2) for "127.0.0.1" port 3000 (Errno::EADDRINUSE)`}, 3000},
+		{"vite", []string{"Port 5173 is in use, trying another one..."}, 5173},
+		{"clock prefix", []string{"12:30:45 Error: listen EADDRINUSE: address already in use :::3000"}, 3000},
+		{"bracketed clock", []string{"[12:30:45.120] listen EADDRINUSE 127.0.0.1:8080"}, 8080},
+		{"ISO timestamp", []string{"2024-01-01T12:30:45Z listen tcp :8080: bind: address already in use"}, 8080},
+		{"clock without port", []string{"12:30:45 Error: address already in use"}, 0},
+		{"short IPv4 port", []string{"9:05 address already in use 10.0.0.1:80"}, 80},
+		{"no error", []string{"listening on :3000"}, 0},
+		{
+			"last error wins",
+			[]string{"address already in use :3000", "", "address already in use :3001", "retrying"},
+			3001,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inUsePort(tt.lines); got != tt.want {
+				t.Errorf("inUsePort(%q) = %d, want %d", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInUsePortOnlyScansBottom(t *testing.T) {
+	lines := []string{"address already in use :3000"}
+	for range conflictScanLines {
+		lines = append(lines, "output")
+	}
+	if got := inUsePort(lines); got != 0 {
+		t.Errorf("inUsePort found port %d above the scanned lines", got)
+	}
+}
@@ -5,28 +5,58 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+
+	"github.com/allenan/herd/internal/session"
 )
 
-// DetectListeningPort checks whether any descendant process of the given
-// tmux pane is listening on a TCP port. Returns the lowest port number
-// found, or 0 if none.
-func DetectListeningPort(paneID string) int {
-	// Get the pane's PID
-	pidStr, err := TmuxRunOutput("display-message", "-p", "-t", paneID, "#{pane_pid}")
-	if err != nil {
-		return 0
-	}
-	pidStr = strings.TrimSpace(pidStr)
-	rootPID, err := strconv.Atoi(pidStr)
+// DetectListeners returns the TCP ports that the processes of the given
+// tmux pane (the shell and its descendants) are listening on, sorted by
+// port. On Linux this reads /proc directly; elsewhere it walks the tree
+// with pgrep and asks lsof.
+func DetectListeners(paneID string) []session.Listener {
+	rootPID, err := PanePID(paneID)
 	if err != nil {
-		return 0
+		return nil
 	}
 
-	// Collect all descendant PIDs (depth-limited)
-	pids := collectDescendants(rootPID, 5)
-	pids = append(pids, rootPID)
+	var listeners []session.Listener
+	if proc, ok := nativeProcFS(); ok {
+		listeners = proc.listeners(rootPID)
+	} else {
+		// Collect all descendant PIDs (depth-limited)
+		pids := collectDescendants(rootPID, maxProcDepth)
+		pids = append(pids, rootPID)
+		listeners = findListeners(pids)
+	}
+	return dedupListeners(listeners)
+}
+
+// ServicePort picks the port a terminal is named after: the lowest one
+// outside the ephemeral range, or 0 if there is none.
+func ServicePort(listeners []session.Listener) int {
+	for _, l := range listeners {
+		if servicePort(l.Port) {
+			return l.Port
+		}
+	}
+	return 0
+}
 
-	return findListeningPort(pids)
+// dedupListeners sorts listeners and drops repeats of the same port and
+// address (e.g. one socket shared by forked workers).
+func dedupListeners(ls []session.Listener) []session.Listener {
+	session.SortListeners(ls)
+	out := ls[:0]
+	for _, l := range ls {
+		if n := len(out); n > 0 && out[n-1].Port == l.Port && out[n-1].Addr == l.Addr {
+			continue
+		}
+		out = append(out, l)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
 }
 
 // collectDescendants walks the process tree from rootPID down to maxDepth
@@ -56,11 +86,11 @@ func collectDescendants(rootPID, maxDepth int) []int {
 	return pids
 }
 
-// findListeningPort runs lsof to find TCP LISTEN sockets for any of the
-// given PIDs. Returns the lowest port number found, or 0 if none.
-func findListeningPort(pids []int) int {
+// findListeners runs lsof to find TCP LISTEN sockets for any of the given
+// PIDs.
+func findListeners(pids []int) []session.Listener {
 	if len(pids) == 0 {
-		return 0
+		return nil
 	}
 
 	// Build a comma-separated PID list for lsof
@@ -76,53 +106,59 @@ func findListeningPort(pids []int) int {
 		"-a", "-p", strings.Join(pidStrs, ","),
 	).Output()
 	if err != nil {
-		return 0
+		return nil
 	}
 
-	lowestPort := 0
+	var listeners []session.Listener
 	for _, line := range strings.Split(string(out), "\n") {
-		port := parseLsofPort(line)
-		if port > 0 && (lowestPort == 0 || port < lowestPort) {
-			lowestPort = port
+		if l, ok := parseLsofListener(line); ok {
+			listeners = append(listeners, l)
 		}
 	}
-	return lowestPort
+	return listeners
 }
 
-// parseLsofPort extracts the port number from an lsof output line.
+// parseLsofListener extracts the listener from an lsof output line.
 // Example line: "node    12345 user   20u  IPv4 0x... TCP *:3000 (LISTEN)"
-// The name field contains something like "*:3000" or "127.0.0.1:8080".
-func parseLsofPort(line string) int {
+// The name field contains something like "*:3000", "127.0.0.1:8080" or
+// "[::1]:9229".
+func parseLsofListener(line string) (session.Listener, bool) {
 	// Look for the TCP field pattern: <addr>:<port>
 	// It appears before "(LISTEN)" in the line
 	idx := strings.Index(line, "(LISTEN)")
 	if idx < 0 {
-		return 0
+		return session.Listener{}, false
 	}
 	// Work backwards from "(LISTEN)" to find the port
 	before := strings.TrimSpace(line[:idx])
 	fields := strings.Fields(before)
-	if len(fields) == 0 {
-		return 0
+	if len(fields) < 5 {
+		return session.Listener{}, false
 	}
 	addrPort := fields[len(fields)-1]
 	colonIdx := strings.LastIndex(addrPort, ":")
 	if colonIdx < 0 {
-		return 0
+		return session.Listener{}, false
 	}
 	port, err := strconv.Atoi(addrPort[colonIdx+1:])
-	if err != nil {
-		return 0
-	}
-	// Sanity check
-	if port < 1 || port > 65535 {
-		return 0
-	}
-	// Skip ephemeral ports (likely not user services)
-	if port > 49151 {
-		return 0
+	if err != nil || port < 1 || port > 65535 {
+		return session.Listener{}, false
+	}
+	addr := strings.Trim(addrPort[:colonIdx], "[]")
+	if addr == "*" {
+		// Wildcard: the TYPE column says which family
+		addr = "0.0.0.0"
+		if fields[4] == "IPv6" {
+			addr = "::"
+		}
 	}
-	return port
+	return session.Listener{Port: port, Addr: addr, Process: strings.ReplaceAll(fields[0], `\x20`, " ")}, true
+}
+
+// servicePort reports whether port looks like a user service: valid and
+// outside the ephemeral range.
+func servicePort(port int) bool {
+	return port >= 1 && port <= 49151
 }
 
 // PanePID returns the PID of the process running in a tmux pane.
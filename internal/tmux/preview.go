@@ -0,0 +1,111 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	gotmux "github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// previewMarker identifies preview panes by their start command.
+const previewMarker = "preview --pane"
+
+// previewHeight is the size of the preview split below the viewport.
+const previewHeight = "40%"
+
+// isPreviewPane reports whether a window 0 pane is the temporary preview split.
+func (m *Manager) isPreviewPane(p *gotmux.Pane) bool {
+	return p.Id == m.previewPaneID || strings.Contains(p.StartCommand, previewMarker)
+}
+
+// previewArgs builds the command line for a preview pane tailing paneID.
+func previewArgs(paneID, title string) ([]string, error) {
+	selfBin, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	args := []string{selfBin, "preview", "--pane", paneID, "--title", title}
+	if profileName != "" {
+		args = append(args, "--profile", profileName)
+	}
+	return args, nil
+}
+
+// ShowPreview displays a live, read-only tail of the given session in a
+// temporary split below the viewport. If a preview is already open it is
+// retargeted in place. Unlike SwitchTo, this never swaps panes, moves focus,
+// or touches LastActiveSession, so the session's status is left as-is.
+func (m *Manager) ShowPreview(sessionID string) error {
+	sess := m.State.FindByID(sessionID)
+	if sess == nil {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	if m.previewSessionID == sessionID && m.previewPaneID != "" && paneExists(m.previewPaneID) {
+		return nil
+	}
+
+	args, err := previewArgs(sess.TmuxPaneID, sess.DisplayName())
+	if err != nil {
+		return err
+	}
+
+	if m.previewPaneID != "" && paneExists(m.previewPaneID) {
+		respawn := append([]string{"respawn-pane", "-k", "-t", m.previewPaneID}, args...)
+		if err := TmuxRun(respawn...); err != nil {
+			return fmt.Errorf("failed to retarget preview: %w", err)
+		}
+		m.previewSessionID = sessionID
+		return nil
+	}
+
+	viewportPaneID, err := m.resolveViewportPane()
+	if err != nil {
+		return fmt.Errorf("no viewport pane: %w", err)
+	}
+
+	split := append([]string{
+		"split-window", "-v", "-d", "-l", previewHeight,
+		"-P", "-F", "#{pane_id}",
+		"-t", viewportPaneID,
+	}, args...)
+	out, err := TmuxRunOutput(split...)
+	if err != nil {
+		return fmt.Errorf("failed to open preview: %w", err)
+	}
+	m.previewPaneID = strings.TrimSpace(out)
+	m.previewSessionID = sessionID
+	debugLog.Printf("ShowPreview: session=%s previewPane=%s", sessionID, m.previewPaneID)
+	return nil
+}
+
+// HidePreview closes the preview split, if any.
+func (m *Manager) HidePreview() {
+	if m.previewPaneID != "" {
+		TmuxRun("kill-pane", "-t", m.previewPaneID)
+		debugLog.Printf("HidePreview: killed preview pane %s", m.previewPaneID)
+	}
+	m.previewPaneID = ""
+	m.previewSessionID = ""
+}
+
+// KillStalePreviews removes preview splits left behind by a sidebar that
+// restarted while previewing; the new one doesn't know their pane IDs.
+func (m *Manager) KillStalePreviews() {
+	out, err := TmuxRunOutput("list-panes", "-t", SessionName()+":0", "-F", "#{pane_id} #{pane_start_command}")
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		paneID, command, _ := strings.Cut(line, " ")
+		if strings.Contains(command, previewMarker) && paneID != m.previewPaneID {
+			TmuxRun("kill-pane", "-t", paneID)
+			debugLog.Printf("KillStalePreviews: killed preview pane %s", paneID)
+		}
+	}
+}
+
+// PreviewSessionID returns the session currently shown in the preview split.
+func (m *Manager) PreviewSessionID() string {
+	return m.previewSessionID
+}
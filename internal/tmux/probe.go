@@ -0,0 +1,60 @@
+package tmux
+
+import (
+	"sync"
+	"time"
+)
+
+// probeInterval is how often a background probe of a session is rerun.
+const probeInterval = 2 * time.Second
+
+// prober runs a slow per-session check (a service's ready_cmd, the
+// command line of a terminal's job) in the background, so the status
+// refresh on the UI loop only reads the last result. The zero value is
+// ready to use.
+type prober[T any] struct {
+	mu      sync.Mutex
+	results map[string]*probeResult[T] // session ID → latest result
+}
+
+type probeResult[T any] struct {
+	running bool
+	value   T
+	at      time.Time // when the last run finished
+}
+
+// latest returns the last result of probe for a session, the zero value
+// before the first run has finished. It starts another run when none is
+// in flight and the last one is older than probeInterval.
+func (p *prober[T]) latest(sessionID string, probe func() T) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.results == nil {
+		p.results = make(map[string]*probeResult[T])
+	}
+	r := p.results[sessionID]
+	if r == nil {
+		r = &probeResult[T]{}
+		p.results[sessionID] = r
+	}
+	if !r.running && time.Since(r.at) >= probeInterval {
+		r.running = true
+		go func() {
+			v := probe()
+			p.mu.Lock()
+			r.running = false
+			r.value = v
+			r.at = time.Now()
+			p.mu.Unlock()
+		}()
+	}
+	return r.value
+}
+
+// forget drops the result for a session, so the next check starts afresh.
+// A run still in flight reports to the dropped entry.
+func (p *prober[T]) forget(sessionID string) {
+	p.mu.Lock()
+	delete(p.results, sessionID)
+	p.mu.Unlock()
+}
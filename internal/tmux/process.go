@@ -0,0 +1,165 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/shellquote"
+)
+
+// stopGrace is how long a stopped command gets to exit after SIGINT before
+// it is sent SIGTERM.
+const stopGrace = 3 * time.Second
+
+// restartTimeout bounds how long a restarted terminal is shown as
+// restarting while waiting for its port to come back.
+const restartTimeout = 2 * time.Minute
+
+// foregroundProcess returns the foreground process group of a pane's
+// terminal and the command line of its leader. The group is the shell's
+// own when nothing is running.
+func foregroundProcess(paneID string) (shellPID, pgid int, command string, err error) {
+	shellPID, err = PanePID(paneID)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if proc, ok := nativeProcFS(); ok {
+		pgid, ok = proc.foregroundGroup(shellPID)
+		if !ok {
+			return 0, 0, "", fmt.Errorf("failed to read foreground process of pane %s", paneID)
+		}
+		return shellPID, pgid, shellquote.Join(proc.cmdline(pgid)...), nil
+	}
+
+	out, err := exec.Command("ps", "-o", "tpgid=", "-p", strconv.Itoa(shellPID)).Output()
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to read foreground process of pane %s: %w", paneID, err)
+	}
+	if pgid, err = strconv.Atoi(strings.TrimSpace(string(out))); err != nil {
+		return 0, 0, "", fmt.Errorf("failed to read foreground process of pane %s: %w", paneID, err)
+	}
+	out, _ = exec.Command("ps", "-o", "command=", "-p", strconv.Itoa(pgid)).Output()
+	return shellPID, pgid, strings.TrimSpace(string(out)), nil
+}
+
+// jobCommand returns the command line of the job running in a pane, for
+// rerunning it later, or "" when the shell is idle or the line can't be
+// rebuilt. It is rebuilt from the arguments of the job's leader, so only
+// simple commands round-trip: environment assignments (FOO=1 cmd) and the
+// rest of a && or ; chain are lost, and pipelines, whose stages are
+// separate processes, are skipped entirely.
+func jobCommand(paneID string) string {
+	shellPID, pgid, command, err := foregroundProcess(paneID)
+	if err != nil || pgid == shellPID {
+		return ""
+	}
+	if pipelineStages(shellPID, pgid) > 1 {
+		return ""
+	}
+	return command
+}
+
+// pipelineStages counts the shell's children in the foreground process
+// group: one per stage of a pipeline.
+func pipelineStages(shellPID, pgid int) int {
+	if proc, ok := nativeProcFS(); ok {
+		n := 0
+		for _, child := range proc.children(shellPID) {
+			if fields, ok := proc.statFields(child); ok && len(fields) > 2 && fields[2] == strconv.Itoa(pgid) {
+				n++
+			}
+		}
+		return n
+	}
+	out, _ := exec.Command("pgrep", "-P", strconv.Itoa(shellPID), "-g", strconv.Itoa(pgid)).Output()
+	return len(strings.Fields(string(out)))
+}
+
+// StopTerminal interrupts whatever is running in a terminal session, like
+// pressing Ctrl-C, and sends SIGTERM if it is still there after stopGrace.
+func (m *Manager) StopTerminal(sessionID string) error {
+	m.reloadState()
+	s := m.State.FindByID(sessionID)
+	if s == nil || s.Type != session.TypeTerminal {
+		return fmt.Errorf("not a terminal session")
+	}
+	shellPID, pgid, _, err := foregroundProcess(s.TmuxPaneID)
+	if err != nil {
+		return err
+	}
+	if pgid <= 0 || pgid == shellPID {
+		return fmt.Errorf("nothing running")
+	}
+
+	debugLog.Printf("StopTerminal: SIGINT to process group %d in pane %s", pgid, s.TmuxPaneID)
+	if err := syscall.Kill(-pgid, syscall.SIGINT); err != nil {
+		return fmt.Errorf("failed to stop process group %d: %w", pgid, err)
+	}
+	go func() {
+		time.Sleep(stopGrace)
+		if syscall.Kill(-pgid, 0) == nil {
+			debugLog.Printf("StopTerminal: process group %d still running, sending SIGTERM", pgid)
+			syscall.Kill(-pgid, syscall.SIGTERM)
+		}
+	}()
+	s.RestartingAt = time.Time{}
+	m.State.Save(m.StatePath)
+	return nil
+}
+
+// RestartTerminal reruns the last command recorded for a terminal session.
+// The pane is respawned, which kills the current run, with a fresh shell
+// that the command is typed into, so Ctrl-C and job control work as usual
+// and the terminal keeps its place in the layout. With clear, the
+// scrollback is dropped first.
+func (m *Manager) RestartTerminal(sessionID string, clear bool) error {
+	m.reloadState()
+	s := m.State.FindByID(sessionID)
+	if s == nil || s.Type != session.TypeTerminal {
+		return fmt.Errorf("not a terminal session")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("no command to rerun")
+	}
+
+	debugLog.Printf("RestartTerminal: pane %s command %q clear=%v", s.TmuxPaneID, s.Command, clear)
+	args := append([]string{"respawn-pane", "-k", "-t", s.TmuxPaneID, "-c", s.Dir}, portEnv(s.Port)...)
+	if err := TmuxRun(args...); err != nil {
+		return fmt.Errorf("failed to restart: %w", err)
+	}
+	if clear {
+		TmuxRun("clear-history", "-t", s.TmuxPaneID)
+	}
+	if err := TmuxRun("send-keys", "-t", s.TmuxPaneID, s.Command, "Enter"); err != nil {
+		return fmt.Errorf("failed to rerun %q: %w", s.Command, err)
+	}
+
+	// Shown as restarting until the port it had comes back
+	s.RestartingAt = time.Now()
+	s.RestartPort = s.ServicePort
+	s.SetStatus(session.StatusRunning, time.Now())
+	m.State.Save(m.StatePath)
+	return nil
+}
+
+// restartSettled reports whether a restarting terminal is back: its old
+// port is listening again, or, without one, its command is running.
+func restartSettled(s *session.Session, status session.Status) bool {
+	if time.Since(s.RestartingAt) > restartTimeout {
+		return true
+	}
+	if s.RestartPort > 0 {
+		for _, l := range s.Listeners {
+			if l.Port == s.RestartPort {
+				return true
+			}
+		}
+		return false
+	}
+	return status == session.StatusRunning || status == session.StatusService
+}
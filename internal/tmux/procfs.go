@@ -0,0 +1,253 @@
+package tmux
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// procRoot is where the Linux process filesystem is mounted.
+const procRoot = "/proc"
+
+// maxProcDepth bounds how far below a pane's shell descendants are looked
+// for.
+const maxProcDepth = 5
+
+// procFS reads process and socket information from a /proc tree. The root
+// is a parameter so the parsing can be pointed at a fixture tree.
+type procFS struct {
+	root string
+}
+
+// nativeProcFS returns the host's /proc, or false where there is none
+// (macOS), in which case callers fall back to pgrep and lsof.
+func nativeProcFS() (procFS, bool) {
+	if runtime.GOOS != "linux" {
+		return procFS{}, false
+	}
+	if _, err := os.Stat(filepath.Join(procRoot, "net", "tcp")); err != nil {
+		return procFS{}, false
+	}
+	return procFS{root: procRoot}, true
+}
+
+// listeners returns the LISTEN sockets held by rootPID and its
+// descendants.
+func (p procFS) listeners(rootPID int) []session.Listener {
+	pids := append([]int{rootPID}, p.descendants(rootPID, maxProcDepth)...)
+
+	owner := make(map[string]int) // socket inode → PID
+	for _, pid := range pids {
+		for _, inode := range p.socketInodes(pid) {
+			if _, ok := owner[inode]; !ok {
+				owner[inode] = pid
+			}
+		}
+	}
+	if len(owner) == 0 {
+		return nil
+	}
+
+	var out []session.Listener
+	for _, table := range []string{"tcp", "tcp6"} {
+		for _, l := range p.tcpListeners(table) {
+			if pid, ok := owner[l.inode]; ok {
+				out = append(out, session.Listener{Port: l.port, Addr: l.addr, Process: p.comm(pid)})
+			}
+		}
+	}
+	return out
+}
+
+// comm returns the process name of pid.
+func (p procFS) comm(pid int) string {
+	data, err := os.ReadFile(filepath.Join(p.root, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// descendants returns the PIDs below pid, down to maxDepth levels.
+func (p procFS) descendants(pid, maxDepth int) []int {
+	if maxDepth <= 0 {
+		return nil
+	}
+	var out []int
+	for _, child := range p.children(pid) {
+		out = append(out, child)
+		out = append(out, p.descendants(child, maxDepth-1)...)
+	}
+	return out
+}
+
+// children lists the direct children of pid from
+// /proc/<pid>/task/*/children, falling back to scanning every process's
+// parent when the kernel doesn't provide those files.
+func (p procFS) children(pid int) []int {
+	files, _ := filepath.Glob(filepath.Join(p.root, strconv.Itoa(pid), "task", "*", "children"))
+	if len(files) == 0 {
+		return p.childrenByScan(pid)
+	}
+	var out []int
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, field := range strings.Fields(string(data)) {
+			if child, err := strconv.Atoi(field); err == nil {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+func (p procFS) childrenByScan(pid int) []int {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil
+	}
+	var out []int
+	for _, e := range entries {
+		child, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, ok := p.parent(child); ok && ppid == pid {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// parent reads the parent PID from /proc/<pid>/stat.
+func (p procFS) parent(pid int) (int, bool) {
+	fields, ok := p.statFields(pid)
+	if !ok || len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	return ppid, err == nil
+}
+
+// statFields returns the fields of /proc/<pid>/stat after the command name:
+// state, ppid, pgrp, session, tty_nr, tpgid, ... The name in parentheses
+// may contain spaces, so fields are counted after the last ')'.
+func (p procFS) statFields(pid int) ([]string, bool) {
+	data, err := os.ReadFile(filepath.Join(p.root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, false
+	}
+	s := string(data)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return nil, false
+	}
+	return strings.Fields(s[i+1:]), true
+}
+
+// foregroundGroup returns the foreground process group of pid's terminal
+// (tpgid, the eighth field of /proc/<pid>/stat).
+func (p procFS) foregroundGroup(pid int) (int, bool) {
+	fields, ok := p.statFields(pid)
+	if !ok || len(fields) < 6 {
+		return 0, false
+	}
+	tpgid, err := strconv.Atoi(fields[5])
+	return tpgid, err == nil
+}
+
+// cmdline returns the arguments pid was started with.
+func (p procFS) cmdline(pid int) []string {
+	data, err := os.ReadFile(filepath.Join(p.root, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
+
+// socketInodes returns the inodes of the sockets pid has open, from the
+// "socket:[12345]" links in /proc/<pid>/fd.
+func (p procFS) socketInodes(pid int) []string {
+	dir := filepath.Join(p.root, strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		target, err := os.Readlink(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			out = append(out, strings.TrimSuffix(inode, "]"))
+		}
+	}
+	return out
+}
+
+// tcpSocket is a LISTEN entry of /proc/net/tcp or tcp6.
+type tcpSocket struct {
+	addr  string
+	port  int
+	inode string
+}
+
+// tcpListStateListen is TCP_LISTEN in the st column.
+const tcpListStateListen = "0A"
+
+// tcpListeners parses /proc/net/<table> and returns its LISTEN sockets.
+// Lines look like:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 905 ...
+func (p procFS) tcpListeners(table string) []tcpSocket {
+	f, err := os.Open(filepath.Join(p.root, "net", table))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var out []tcpSocket
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 || fields[3] != tcpListStateListen {
+			continue
+		}
+		hexAddr, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(hexPort, 16, 16)
+		if err != nil {
+			continue
+		}
+		out = append(out, tcpSocket{addr: decodeProcAddr(hexAddr), port: int(port), inode: fields[9]})
+	}
+	return out
+}
+
+// decodeProcAddr turns a /proc/net address into text. The kernel prints
+// the address as 32-bit words in host (little-endian) byte order.
+func decodeProcAddr(h string) string {
+	b, err := hex.DecodeString(h)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return h
+	}
+	for i := 0; i+4 <= len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+	return net.IP(b).String()
+}
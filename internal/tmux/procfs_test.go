@@ -0,0 +1,127 @@
+package tmux
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// fixtureProc is a /proc tree with a shell (100) running a server whose
+// name has spaces and a ')' (200) and make (300), which runs vite (400).
+// 300 has no task/*/children files, so its children are found by scanning
+// every process's stat. 500 is unrelated.
+var fixtureProc = procFS{root: "testdata/proc"}
+
+func TestProcChildren(t *testing.T) {
+	tests := []struct {
+		name string
+		pid  int
+		want []int
+	}{
+		{"from task children", 100, []int{200, 300}},
+		{"empty children file", 200, nil},
+		{"stat scan fallback", 300, []int{400}},
+		{"missing process", 999, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fixtureProc.children(tt.pid); !slices.Equal(got, tt.want) {
+				t.Errorf("children(%d) = %v, want %v", tt.pid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcDescendants(t *testing.T) {
+	if got, want := fixtureProc.descendants(100, maxProcDepth), []int{200, 300, 400}; !slices.Equal(got, want) {
+		t.Errorf("descendants(100) = %v, want %v", got, want)
+	}
+	if got, want := fixtureProc.descendants(100, 1), []int{200, 300}; !slices.Equal(got, want) {
+		t.Errorf("descendants(100, depth 1) = %v, want %v", got, want)
+	}
+}
+
+func TestProcStatWithParenthesizedName(t *testing.T) {
+	ppid, ok := fixtureProc.parent(200)
+	if !ok || ppid != 100 {
+		t.Errorf("parent(200) = %d, %t, want 100", ppid, ok)
+	}
+	tpgid, ok := fixtureProc.foregroundGroup(200)
+	if !ok || tpgid != 300 {
+		t.Errorf("foregroundGroup(200) = %d, %t, want 300", tpgid, ok)
+	}
+	if got := fixtureProc.comm(200); got != "web) srv x" {
+		t.Errorf("comm(200) = %q, want %q", got, "web) srv x")
+	}
+	if _, ok := fixtureProc.parent(999); ok {
+		t.Error("parent(999) found a missing process")
+	}
+}
+
+func TestProcSocketInodes(t *testing.T) {
+	got := fixtureProc.socketInodes(200)
+	slices.Sort(got)
+	if want := []string{"1001", "1002", "1004"}; !slices.Equal(got, want) {
+		t.Errorf("socketInodes(200) = %v, want %v", got, want)
+	}
+	if got := fixtureProc.socketInodes(100); len(got) != 0 {
+		t.Errorf("socketInodes(100) = %v, want none", got)
+	}
+}
+
+func TestProcTCPListeners(t *testing.T) {
+	tests := []struct {
+		table string
+		want  []tcpSocket
+	}{
+		{"tcp", []tcpSocket{
+			{addr: "127.0.0.1", port: 8080, inode: "1001"},
+			{addr: "0.0.0.0", port: 22, inode: "9999"},
+		}},
+		{"tcp6", []tcpSocket{
+			{addr: "::1", port: 3000, inode: "1002"},
+			{addr: "127.0.0.1", port: 5173, inode: "1003"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := fixtureProc.tcpListeners(tt.table); !slices.Equal(got, tt.want) {
+			t.Errorf("tcpListeners(%s) = %+v, want %+v", tt.table, got, tt.want)
+		}
+	}
+}
+
+func TestProcListeners(t *testing.T) {
+	want := []session.Listener{
+		{Port: 8080, Addr: "127.0.0.1", Process: "web) srv x"},
+		{Port: 3000, Addr: "::1", Process: "web) srv x"},
+		{Port: 5173, Addr: "127.0.0.1", Process: "vite"},
+	}
+	if got := fixtureProc.listeners(100); !slices.Equal(got, want) {
+		t.Errorf("listeners(100) = %+v, want %+v", got, want)
+	}
+	if got := fixtureProc.listeners(500); got != nil {
+		t.Errorf("listeners(500) = %+v, want none", got)
+	}
+}
+
+func TestDecodeProcAddr(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0100007F", "127.0.0.1"},
+		{"00000000", "0.0.0.0"},
+		{"0101A8C0", "192.168.1.1"},
+		{"00000000000000000000000001000000", "::1"},
+		{"00000000000000000000000000000000", "::"},
+		{"0000000000000000FFFF00000100007F", "127.0.0.1"},
+		{"B80D0120000000000000000001000000", "2001:db8::1"},
+		{"zz", "zz"},
+		{"0100", "0100"},
+	}
+	for _, tt := range tests {
+		if got := decodeProcAddr(tt.in); got != tt.want {
+			t.Errorf("decodeProcAddr(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
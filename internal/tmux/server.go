@@ -6,8 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/allenan/herd/internal/profile"
+	"github.com/allenan/herd/internal/shellquote"
 	gotmux "github.com/GianlucaP106/gotmux/gotmux"
 )
 
@@ -16,8 +19,13 @@ const herdEnvVar = "HERD_ACTIVE"
 // Package-level state set by Init(). There is exactly one profile per process.
 var (
 	baseDir        string
+	profileName    string
 	sessionName    string
 	claudeConfigDir string
+	profileEnv     map[string]string
+	pathPrefix     []string
+	claudeArgs     []string
+	accentColor    string
 	debugLog       *log.Logger
 )
 
@@ -25,18 +33,128 @@ var (
 // once per process before any other function in this package.
 func Init(prof *profile.Profile) {
 	baseDir = prof.BaseDir
+	profileName = prof.Name
 	sessionName = prof.TmuxSessionName()
 	claudeConfigDir = prof.ClaudeConfigDir
+	profileEnv = prof.Env
+	pathPrefix = prof.PathPrefix
+	claudeArgs = prof.ClaudeArgs
+	accentColor = prof.AccentColor
 	initDebugLog(prof.LogPath())
 }
 
+// envOption is the tmux server option listing the profile env vars herd
+// set, so ones removed from the config can be unset.
+const envOption = "@herd-env"
+
+// Server options holding the PATH and active border style the server had
+// before ApplyEnv overrode them, to restore once the config drops them.
+const (
+	savedPathOption   = "@herd-saved-path"
+	savedBorderOption = "@herd-saved-border-style"
+)
+
 // ApplyEnv sets profile-specific environment variables on the tmux server.
 // If ClaudeConfigDir is set, it becomes a global tmux env var so all new
-// panes/windows inherit it.
-func ApplyEnv() {
+// panes/windows inherit it, as do the profile's env vars and PATH prefix.
+// Vars dropped from the config since the last call are unset, and a
+// dropped PATH prefix or accent color is undone.
+func ApplyEnv() error {
+	var cmds [][]string
 	if claudeConfigDir != "" {
-		TmuxRun("set-environment", "-g", "CLAUDE_CONFIG_DIR", claudeConfigDir)
+		cmds = append(cmds, []string{"set-environment", "-g", "CLAUDE_CONFIG_DIR", claudeConfigDir})
+	}
+
+	if out, err := TmuxRunOutput("show-options", "-gqv", envOption); err == nil {
+		for _, name := range strings.Fields(out) {
+			if _, ok := profileEnv[name]; !ok {
+				cmds = append(cmds, []string{"set-environment", "-gu", name})
+			}
+		}
+	}
+	var set []string
+	for name, value := range profileEnv {
+		if value == "" {
+			cmds = append(cmds, []string{"set-environment", "-gu", name})
+		} else {
+			cmds = append(cmds, []string{"set-environment", "-g", name, value})
+		}
+		set = append(set, name)
+	}
+	cmds = append(cmds, []string{"set-option", "-g", envOption, strings.Join(set, " ")})
+
+	savedPath := globalOption(savedPathOption)
+	switch {
+	case len(pathPrefix) > 0:
+		if savedPath == "" {
+			out, _ := TmuxRunOutput("show-environment", "-g", "PATH")
+			if path, ok := strings.CutPrefix(strings.TrimSpace(out), "PATH="); ok {
+				cmds = append(cmds, []string{"set-option", "-g", savedPathOption, path})
+			}
+		}
+		cmds = append(cmds, []string{"set-environment", "-g", "PATH", prefixPath(os.Getenv("PATH"), pathPrefix)})
+	case savedPath != "":
+		cmds = append(cmds,
+			[]string{"set-environment", "-g", "PATH", savedPath},
+			[]string{"set-option", "-gu", savedPathOption})
+	}
+
+	savedBorder := globalOption(savedBorderOption)
+	switch {
+	case accentColor != "":
+		if savedBorder == "" {
+			if border := globalOption("pane-active-border-style"); border != "" {
+				cmds = append(cmds, []string{"set-option", "-g", savedBorderOption, border})
+			}
+		}
+		cmds = append(cmds, []string{"set-option", "-g", "pane-active-border-style", "fg=" + tmuxColor(accentColor)})
+	case savedBorder != "":
+		cmds = append(cmds,
+			[]string{"set-option", "-g", "pane-active-border-style", savedBorder},
+			[]string{"set-option", "-gu", savedBorderOption})
+	}
+
+	for _, args := range cmds {
+		if err := TmuxRun(args...); err != nil {
+			return fmt.Errorf("failed to apply profile environment (tmux %s): %w", strings.Join(args[:2], " "), err)
+		}
 	}
+	return nil
+}
+
+// globalOption returns the value of a global tmux option, "" if unset.
+func globalOption(name string) string {
+	out, _ := TmuxRunOutput("show-options", "-gqv", name)
+	return strings.TrimSpace(out)
+}
+
+// prefixPath puts dirs in front of path, dropping their other occurrences
+// so re-applying the prefix doesn't grow PATH.
+func prefixPath(path string, dirs []string) string {
+	parts := slices.Clone(dirs)
+	for _, dir := range filepath.SplitList(path) {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			parts = append(parts, dir)
+		}
+	}
+	return strings.Join(parts, string(filepath.ListSeparator))
+}
+
+// tmuxColor converts a profile accent color to tmux's syntax.
+func tmuxColor(c string) string {
+	if strings.HasPrefix(c, "#") {
+		return c
+	}
+	return "colour" + c
+}
+
+// claudeCommand is the command new Claude sessions run, with the
+// profile's flags.
+func claudeCommand() string {
+	if len(claudeArgs) == 0 {
+		return "claude"
+	}
+	return shellquote.Join(append([]string{"claude"}, claudeArgs...)...)
 }
 
 func initDebugLog(logPath string) {
@@ -91,7 +209,13 @@ func IsInstalled() bool {
 }
 
 func ServerRunning() bool {
-	return tmuxCmd("-S", SocketPath(), "list-sessions").Run() == nil
+	return ServerRunningAt(SocketPath())
+}
+
+// ServerRunningAt reports whether a tmux server answers on the given
+// socket, e.g. another profile's.
+func ServerRunningAt(socketPath string) bool {
+	return tmuxCmd("-S", socketPath, "list-sessions").Run() == nil
 }
 
 func EnsureServer() (*gotmux.Tmux, error) {
@@ -109,6 +233,9 @@ func EnsureServer() (*gotmux.Tmux, error) {
 			return nil, fmt.Errorf("failed to create tmux server: %w", err)
 		}
 	}
+	if err := ApplyEnv(); err != nil {
+		return nil, err
+	}
 	return GetClient()
 }
 
@@ -132,6 +259,16 @@ func TmuxRunOutput(args ...string) (string, error) {
 	return string(out), err
 }
 
+// ReplaceClients detaches the clients attached to a session of the tmux
+// server at socketPath, possibly another profile's, and has each run argv
+// in its place, in the terminal the client ran in. Used to move a terminal
+// from one profile's herd to another's.
+func ReplaceClients(socketPath, session string, argv []string) error {
+	// Clients carry HERD_ACTIVE, which would stop herd from starting
+	line := "exec env -u " + herdEnvVar + " " + shellquote.Join(argv...)
+	return tmuxCmd("-S", socketPath, "detach-client", "-s", session, "-E", line).Run()
+}
+
 func Attach() error {
 	cmd := tmuxCmd("-S", SocketPath(), "attach-session", "-t", SessionName())
 	cmd.Stdin = os.Stdin
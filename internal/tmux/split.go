@@ -0,0 +1,135 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// slotIndex returns the position of paneID in slots, or -1.
+func slotIndex(slots []string, paneID string) int {
+	for i, id := range slots {
+		if id == paneID {
+			return i
+		}
+	}
+	return -1
+}
+
+// SplitViewport adds a viewport slot next to the active one. sideBySide
+// splits left/right; otherwise the new slot is stacked below. The new slot
+// starts with the placeholder and becomes active, so the next SwitchTo
+// fills it.
+func (m *Manager) SplitViewport(sideBySide bool) error {
+	m.reloadState()
+
+	activePaneID, err := m.resolveViewportPane()
+	if err != nil {
+		return fmt.Errorf("no viewport pane: %w", err)
+	}
+
+	selfBin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	dir := "-v"
+	if sideBySide {
+		dir = "-h"
+	}
+	out, err := TmuxRunOutput(
+		"split-window", dir, "-d",
+		"-P", "-F", "#{pane_id}",
+		"-t", activePaneID,
+		selfBin, "placeholder",
+	)
+	if err != nil {
+		debugLog.Printf("SplitViewport: split-window failed: %v", err)
+		return fmt.Errorf("failed to split viewport: %w", err)
+	}
+	newPaneID := strings.TrimSpace(out)
+
+	slots, err := m.resolveViewportSlots()
+	if err != nil {
+		return err
+	}
+	if idx := slotIndex(slots, newPaneID); idx >= 0 {
+		m.State.ActiveSlot = idx
+		m.setViewportSlots(slots)
+	}
+
+	// Keep the sidebar pinned while the viewport is rearranged
+	TmuxRun("resize-pane", "-t", m.State.SidebarPaneID, "-x", "32")
+
+	debugLog.Printf("SplitViewport: new slot %s, %d slots, active=%d", newPaneID, len(slots), m.State.ActiveSlot)
+	m.State.Save(m.StatePath)
+	return nil
+}
+
+// CloseSlot removes the active viewport slot. A session shown there is moved
+// back to its own window (it keeps running); a placeholder is killed.
+func (m *Manager) CloseSlot() error {
+	m.reloadState()
+
+	slots, err := m.resolveViewportSlots()
+	if err != nil {
+		return err
+	}
+	if len(slots) < 2 {
+		return fmt.Errorf("viewport is not split")
+	}
+
+	m.undock()
+	paneID := slots[m.State.ActiveSlot]
+	if sess := m.State.FindByPaneID(paneID); sess != nil {
+		if err := TmuxRun("break-pane", "-d", "-s", paneID, "-n", sessionWindowLabel(sess)); err != nil {
+			debugLog.Printf("CloseSlot: break-pane %s failed: %v", paneID, err)
+			return fmt.Errorf("failed to close slot: %w", err)
+		}
+	} else if err := TmuxRun("kill-pane", "-t", paneID); err != nil {
+		debugLog.Printf("CloseSlot: kill-pane %s failed: %v", paneID, err)
+		return fmt.Errorf("failed to close slot: %w", err)
+	}
+
+	if m.State.ActiveSlot > 0 {
+		m.State.ActiveSlot--
+	}
+	if _, err := m.resolveViewportSlots(); err != nil {
+		return err
+	}
+	if sess := m.State.FindByPaneID(m.State.ViewportPaneID); sess != nil {
+		m.State.LastActiveSession = sess.ID
+	}
+	m.syncDock()
+
+	debugLog.Printf("CloseSlot: closed slot pane %s, active=%d", paneID, m.State.ActiveSlot)
+	m.State.Save(m.StatePath)
+	return nil
+}
+
+// CycleSlot moves the active slot forward (delta=1) or backward (delta=-1)
+// without changing focus, so the next SwitchTo targets a different slot.
+func (m *Manager) CycleSlot(delta int) {
+	m.reloadState()
+
+	slots, err := m.resolveViewportSlots()
+	if err != nil || len(slots) < 2 {
+		return
+	}
+	m.State.ActiveSlot = (m.State.ActiveSlot + delta + len(slots)) % len(slots)
+	m.setViewportSlots(slots)
+	if sess := m.State.FindByPaneID(m.State.ViewportPaneID); sess != nil {
+		m.State.LastActiveSession = sess.ID
+	}
+	m.syncDock()
+	m.State.Save(m.StatePath)
+}
+
+// SlotInfo returns the 1-based active slot and the slot count. The count
+// is 1 when the viewport is not split.
+func (m *Manager) SlotInfo() (active, total int) {
+	if len(m.State.ViewportSlots) < 2 {
+		return 1, 1
+	}
+	return m.State.ActiveSlot + 1, len(m.State.ViewportSlots)
+}
@@ -2,9 +2,14 @@ package tui
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/allenan/herd/internal/control"
+	"github.com/allenan/herd/internal/notify"
 	"github.com/allenan/herd/internal/session"
 	htmux "github.com/allenan/herd/internal/tmux"
 	"github.com/charmbracelet/bubbles/key"
@@ -19,6 +24,9 @@ const (
 	modeNormal mode = iota
 	modePrompt
 	modeSearch
+	modeTags
+	modeRules
+	modeExport
 )
 
 // claudeSpinner uses the same animation sequence as Claude Code's spinner,
@@ -47,17 +55,29 @@ type App struct {
 	defaultDir   string
 	profileName  string
 	err              string
+	info             string // non-error feedback, shown until the next key
 	focused          bool
 	waitingPopup     bool
 	showHelp         bool
+	previewing       bool
+	showDetail       bool
 	pendingDelete    *session.Session
+	pendingBulk      []string // session IDs awaiting bulk delete confirmation
 	searchText       string
+	tagText          string
+	tagSessionID     string
 	binaryModTime    time.Time
 	updateAvailable  bool
+	scanningUsage    bool // a transcript scan is in flight
+	exportID         string // session picked for export, while choosing the format
+	exporting        bool   // an export is in flight
 }
 
 func NewApp(manager *htmux.Manager, defaultDir, profileName string) App {
 	sidebar := NewSidebarModel()
+	sidebar.SetView(parseSidebarView(manager.State.SidebarView))
+	sidebar.SetContextWarn(manager.ContextWarnPercent)
+	sidebar.SetMutes(manager.Rules)
 	sidebar.SetSessions(manager.ListSessions())
 	sidebar.SetActive(manager.State.LastActiveSession)
 
@@ -92,6 +112,35 @@ func NewApp(manager *htmux.Manager, defaultDir, profileName string) App {
 
 type statusTickMsg time.Time
 
+// usageScannedMsg carries a transcript scan back to the UI loop.
+type usageScannedMsg htmux.UsageReport
+
+// scanUsage reads Claude transcripts in the background; parsing a long
+// history can take a while.
+func scanUsage(m *htmux.Manager) tea.Cmd {
+	sessions := m.UsageSnapshot()
+	return func() tea.Msg {
+		return usageScannedMsg(m.ScanUsage(sessions))
+	}
+}
+
+// exportedMsg carries a finished export back to the UI loop.
+type exportedMsg struct {
+	path   string
+	format string
+	err    error
+}
+
+// exportSession writes a session export in the background; rebuilding a
+// long conversation from its transcripts can take a while.
+func exportSession(m *htmux.Manager, s session.Session, format string) tea.Cmd {
+	s.Transcripts = slices.Clone(s.Transcripts)
+	return func() tea.Msg {
+		path, err := m.Export(s, format)
+		return exportedMsg{path: path, format: format, err: err}
+	}
+}
+
 func statusTick() tea.Cmd {
 	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
 		return statusTickMsg(t)
@@ -100,11 +149,19 @@ func statusTick() tea.Cmd {
 
 // popupResultMsg is sent when the popup process writes a result file.
 type popupResultMsg struct {
-	Dir    string
-	Mode   string
-	Branch string
+	Dir       string
+	Mode      string
+	Branch    string
+	SessionID string
+	Project   string
+	Action    string
+	Match     *htmux.Match
 }
 
+// ControlMsg carries a request from `herd ctl`, typically fired by a tmux
+// key binding while focus is in the viewport.
+type ControlMsg control.Request
+
 // popupCheckMsg triggers re-checking for the popup result file.
 type popupCheckMsg struct{}
 
@@ -120,7 +177,15 @@ func checkPopupResult(resultPath string) tea.Cmd {
 		if err := json.Unmarshal(data, &result); err != nil {
 			return popupCheckMsg{}
 		}
-		return popupResultMsg{Dir: result.Dir, Mode: result.Mode, Branch: result.Branch}
+		return popupResultMsg{
+			Dir:       result.Dir,
+			Mode:      result.Mode,
+			Branch:    result.Branch,
+			SessionID: result.SessionID,
+			Project:   result.Project,
+			Action:    result.Action,
+			Match:     result.Match,
+		}
 	})
 }
 
@@ -129,6 +194,26 @@ func (a App) Init() tea.Cmd {
 }
 
 func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before := a.selectedID()
+	model, cmd := a.update(msg)
+	// Whatever moved the cursor, from keys to filters to refreshes, the
+	// preview follows it
+	if app, ok := model.(App); ok && app.previewing && app.selectedID() != before {
+		app.syncPreview()
+		model = app
+	}
+	return model, cmd
+}
+
+// selectedID is the ID of the session under the cursor, "" on a header.
+func (a App) selectedID() string {
+	if sel := a.sidebar.Selected(); sel != nil {
+		return sel.ID
+	}
+	return ""
+}
+
+func (a App) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
@@ -146,6 +231,11 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if reconciled || refreshed {
 			a.sidebar.SetSessions(a.manager.ListSessions())
 		}
+		var scan tea.Cmd
+		if !a.scanningUsage {
+			a.scanningUsage = true
+			scan = scanUsage(a.manager)
+		}
 		// Check if the on-disk binary has been updated
 		if !a.updateAvailable && !a.binaryModTime.IsZero() {
 			if binPath, err := os.Executable(); err == nil {
@@ -156,12 +246,33 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
-		return a, statusTick()
+		return a, tea.Batch(statusTick(), scan)
+	case usageScannedMsg:
+		a.scanningUsage = false
+		if a.manager.ApplyUsage(htmux.UsageReport(msg)) {
+			a.sidebar.SetSessions(a.manager.ListSessions())
+		}
+		return a, nil
+	case exportedMsg:
+		a.exporting = false
+		a.info = ""
+		if msg.err != nil {
+			a.err = msg.err.Error()
+			return a, nil
+		}
+		a.info = "exported to " + shortenHome(msg.path)
+		if msg.format == "html" {
+			openURL(msg.path)
+		}
+		return a, nil
 	case spinner.TickMsg:
 		var cmd1, cmd2 tea.Cmd
 		a.spinner, cmd1 = a.spinner.Update(msg)
 		a.termSpinner, cmd2 = a.termSpinner.Update(msg)
 		return a, tea.Batch(cmd1, cmd2)
+	case ControlMsg:
+		cmd := a.runControl(control.Request(msg))
+		return a, cmd
 	case popupCheckMsg:
 		if a.waitingPopup {
 			return a, checkPopupResult(htmux.PopupResultPath())
@@ -175,6 +286,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Mode == "canceled" {
 			return a, nil
 		}
+		if msg.Mode == "palette" {
+			return a.runPaletteResult(msg)
+		}
+		if msg.Mode == "grep" {
+			return a.jumpToMatch(msg)
+		}
 		if msg.Mode == "worktree" {
 			if _, err := a.manager.CreateWorktreeSession(msg.Dir, msg.Branch); err != nil {
 				a.err = err.Error()
@@ -195,6 +312,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.updatePrompt(msg)
 	case modeSearch:
 		return a.updateSearch(msg)
+	case modeTags:
+		return a.updateTags(msg)
+	case modeRules:
+		return a.updateRules(msg)
+	case modeExport:
+		return a.updateExport(msg)
 	default:
 		return a.updateNormal(msg)
 	}
@@ -203,11 +326,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		a.info = ""
 		// Handle pending delete confirmation first
 		if a.pendingDelete != nil {
 			switch msg.String() {
-			case "y", "enter":
-				a.manager.KillSession(a.pendingDelete.ID)
+			case "y", "enter", "a":
+				if msg.String() == "a" {
+					a.manager.KillSessionWithPaired(a.pendingDelete.ID)
+				} else {
+					a.manager.KillSession(a.pendingDelete.ID)
+				}
 				a.sidebar.SetFilter("")
 				a.sidebar.SetSessions(a.manager.ListSessions())
 				a.sidebar.SetActive(a.manager.State.LastActiveSession)
@@ -216,6 +344,20 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.pendingDelete = nil
 			return a, nil
 		}
+		if a.pendingBulk != nil {
+			if s := msg.String(); s == "y" || s == "enter" {
+				for _, id := range a.pendingBulk {
+					if err := a.manager.KillSession(id); err != nil {
+						a.err = err.Error()
+					}
+				}
+				a.sidebar.SetFilter("")
+				a.sidebar.SetSessions(a.manager.ListSessions())
+				a.sidebar.SetActive(a.manager.State.LastActiveSession)
+			}
+			a.pendingBulk = nil
+			return a, nil
+		}
 
 		// Dismiss help on any key except ? itself
 		if a.showHelp && !key.Matches(msg, keys.Help) {
@@ -235,6 +377,7 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if a.sidebar.IsOnProject() {
 				a.sidebar.ToggleCollapse()
 			} else if sel := a.sidebar.Selected(); sel != nil {
+				a.closePreview()
 				if err := a.manager.SwitchTo(sel.ID); err != nil {
 					a.err = err.Error()
 				} else {
@@ -265,12 +408,58 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a.handleWorktree()
 		case key.Matches(msg, keys.Terminal):
 			return a.handleNewTerminal()
+		case key.Matches(msg, keys.PairedTerm):
+			return a.handleNewPairedTerminal()
+		case key.Matches(msg, keys.Services):
+			return a.handleStartServices()
+		case key.Matches(msg, keys.Grep):
+			return a.launchGrep()
+		case key.Matches(msg, keys.Export):
+			if sel := a.sidebar.Selected(); sel != nil {
+				if a.exporting {
+					a.info = "an export is already running"
+				} else {
+					a.exportID = sel.ID
+					a.mode = modeExport
+				}
+			}
+		case key.Matches(msg, keys.ToggleLog):
+			if sel := a.sidebar.Selected(); sel != nil {
+				if _, err := a.manager.ToggleLogging(sel.ID); err != nil {
+					a.err = err.Error()
+				} else {
+					a.err = ""
+				}
+				a.sidebar.SetSessions(a.manager.ListSessions())
+			}
+		case key.Matches(msg, keys.Stop), key.Matches(msg, keys.Restart), key.Matches(msg, keys.Rerun):
+			sel := a.sidebar.Selected()
+			if sel == nil || sel.Type != session.TypeTerminal {
+				a.err = "select a terminal"
+				break
+			}
+			var err error
+			if key.Matches(msg, keys.Stop) {
+				err = a.manager.StopTerminal(sel.ID)
+			} else {
+				err = a.manager.RestartTerminal(sel.ID, key.Matches(msg, keys.Rerun))
+			}
+			if err != nil {
+				a.err = err.Error()
+			} else {
+				a.err = ""
+			}
+			a.sidebar.SetSessions(a.manager.ListSessions())
+		case key.Matches(msg, keys.TogglePair):
+			a.manager.TogglePaired()
+		case key.Matches(msg, keys.AttachTerm):
+			return a.handleTogglePairing()
 		case key.Matches(msg, keys.Delete):
 			if sel := a.sidebar.Selected(); sel != nil {
 				a.pendingDelete = sel
 			}
 		case key.Matches(msg, keys.MoveUp):
-			if a.sidebar.Filter() == "" {
+			if a.sidebar.Filter() == "" && a.sidebar.CurrentView() == viewProject {
 				if a.sidebar.IsOnProject() {
 					project := a.sidebar.CursorProject()
 					if a.manager.MoveProject(project, -1) {
@@ -286,7 +475,7 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case key.Matches(msg, keys.MoveDown):
-			if a.sidebar.Filter() == "" {
+			if a.sidebar.Filter() == "" && a.sidebar.CurrentView() == viewProject {
 				if a.sidebar.IsOnProject() {
 					project := a.sidebar.CursorProject()
 					if a.manager.MoveProject(project, 1) {
@@ -301,14 +490,83 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+		case key.Matches(msg, keys.BulkDelete):
+			if a.sidebar.Filter() == "" {
+				a.err = "filter first (/text or /tag:name)"
+				break
+			}
+			a.pendingBulk = nil
+			for _, s := range a.sidebar.FilteredSessions() {
+				a.pendingBulk = append(a.pendingBulk, s.ID)
+			}
+			if len(a.pendingBulk) == 0 {
+				a.pendingBulk = nil
+			}
+		case key.Matches(msg, keys.EditTags):
+			if sel := a.sidebar.Selected(); sel != nil {
+				a.mode = modeTags
+				a.tagSessionID = sel.ID
+				a.tagText = strings.Join(sel.Tags, " ")
+			}
 		case key.Matches(msg, keys.Search):
 			a.mode = modeSearch
 			a.searchText = ""
 			a.sidebar.SetFilter("")
+		case key.Matches(msg, keys.CycleView):
+			v := a.sidebar.CurrentView().next()
+			a.sidebar.SetView(v)
+			a.manager.SetSidebarView(string(v))
+		case key.Matches(msg, keys.Preview):
+			if a.previewing {
+				a.closePreview()
+			} else {
+				a.previewing = true
+				a.syncPreview()
+			}
+		case key.Matches(msg, keys.SplitRight), key.Matches(msg, keys.SplitDown):
+			a.closePreview()
+			if err := a.manager.SplitViewport(key.Matches(msg, keys.SplitRight)); err != nil {
+				a.err = err.Error()
+			} else {
+				a.err = ""
+			}
+		case key.Matches(msg, keys.NextSlot):
+			a.manager.CycleSlot(1)
+			a.sidebar.SetActive(a.manager.State.LastActiveSession)
+		case key.Matches(msg, keys.CloseSlot):
+			a.closePreview()
+			if err := a.manager.CloseSlot(); err != nil {
+				a.err = err.Error()
+			} else {
+				a.err = ""
+			}
+			a.sidebar.SetActive(a.manager.State.LastActiveSession)
+		case key.Matches(msg, keys.Detail):
+			a.showDetail = !a.showDetail
+		case key.Matches(msg, keys.Ports):
+			a.showPorts()
+		case key.Matches(msg, keys.Overview):
+			a.showOverview()
+		case msg.Type == tea.KeyEscape && a.showDetail:
+			a.showDetail = false
+		case msg.Type == tea.KeyEscape && a.previewing:
+			a.closePreview()
+		case key.Matches(msg, keys.JumpLast):
+			a.runControl(control.Request{Action: control.ActionLast})
+		case key.Matches(msg, keys.JumpInput):
+			a.runControl(control.Request{Action: control.ActionInput})
+		case key.Matches(msg, keys.JumpDone):
+			a.runControl(control.Request{Action: control.ActionDone})
+		case key.Matches(msg, keys.Palette):
+			return a.launchPalette()
 		case key.Matches(msg, keys.Mute):
 			if a.manager.Notifier != nil {
 				a.manager.Notifier.SetMuted(!a.manager.Notifier.IsMuted())
 			}
+		case key.Matches(msg, keys.MuteItem):
+			a.toggleItemMute()
+		case key.Matches(msg, keys.Rules):
+			a.mode = modeRules
 		case key.Matches(msg, keys.Reload):
 			htmux.ReloadSidebar(a.manager.State.SidebarPaneID, a.profileName)
 			return a, nil
@@ -319,6 +577,229 @@ func (a App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// runControl performs an action shared by sidebar keys and `herd ctl`
+// requests. Actions that create or kill sessions target the active session
+// (the one in the viewport), not the cursor, since they are usually
+// triggered while focus is in the viewport.
+func (a *App) runControl(req control.Request) tea.Cmd {
+	active := a.manager.State.FindByID(a.manager.State.LastActiveSession)
+
+	var err error
+	switch req.Action {
+	case control.ActionLast:
+		err = a.manager.SwitchToPrevious()
+	case control.ActionInput:
+		err = a.manager.JumpToNext(session.StatusInput)
+	case control.ActionDone:
+		err = a.manager.JumpToNext(session.StatusPlanReady, session.StatusDone)
+	case control.ActionNext, control.ActionPrev:
+		err = a.switchRelative(req.Action == control.ActionNext)
+	case control.ActionSwitch:
+		if a.manager.State.FindByID(req.SessionID) == nil {
+			err = fmt.Errorf("session %s no longer exists", req.SessionID)
+			break
+		}
+		if err = a.manager.SwitchTo(req.SessionID); err == nil {
+			a.sidebar.SetCursorToSession(req.SessionID)
+		}
+	case control.ActionNew:
+		if active == nil {
+			m, cmd := a.launchPopup("new_project", a.defaultDir, "")
+			*a = m.(App)
+			return cmd
+		}
+		_, err = a.manager.CreateSession(active.Dir, "New Session")
+		a.sidebar.SetFilter("")
+	case control.ActionTerminal:
+		if active == nil {
+			err = fmt.Errorf("no project context for terminal")
+			break
+		}
+		_, err = a.manager.CreateTerminal(active.Dir, active.Project)
+	case control.ActionKill:
+		if active == nil {
+			err = fmt.Errorf("no active session")
+			break
+		}
+		err = a.manager.KillSessionWithPaired(active.ID)
+	default:
+		err = fmt.Errorf("unknown action %q", req.Action)
+	}
+	if err != nil {
+		a.err = err.Error()
+		if !a.focused {
+			// The sidebar status line is easy to miss from the viewport
+			htmux.TmuxRun("display-message", "herd: "+a.err)
+		}
+		return nil
+	}
+	a.err = ""
+	a.closePreview()
+	a.sidebar.SetSessions(a.manager.ListSessions())
+	a.sidebar.SetActive(a.manager.State.LastActiveSession)
+	return nil
+}
+
+// switchRelative switches to the session after (or before) the active one
+// in sidebar order, wrapping around.
+func (a *App) switchRelative(forward bool) error {
+	order := a.sidebar.SessionOrder()
+	if len(order) == 0 {
+		return fmt.Errorf("no sessions")
+	}
+	idx := -1
+	for i, id := range order {
+		if id == a.manager.State.LastActiveSession {
+			idx = i
+			break
+		}
+	}
+	switch {
+	case idx < 0:
+		idx = 0
+	case forward:
+		idx = (idx + 1) % len(order)
+	default:
+		idx = (idx - 1 + len(order)) % len(order)
+	}
+	return a.manager.SwitchTo(order[idx])
+}
+
+// toggleItemMute mutes or unmutes notifications for the project header
+// under the cursor, or for the selected session.
+func (a *App) toggleItemMute() {
+	var err error
+	switch {
+	case a.sidebar.IsOnProject():
+		project := a.sidebar.CursorProject()
+		if project == "" {
+			a.err = "mute a project from the project view"
+			return
+		}
+		_, err = a.manager.ToggleProjectMute(project)
+	case a.sidebar.Selected() != nil:
+		_, err = a.manager.ToggleSessionMute(a.sidebar.Selected().ID)
+	default:
+		return
+	}
+	if err != nil {
+		a.err = err.Error()
+	}
+	a.sidebar.SetMutes(a.manager.Rules)
+}
+
+// ruleKeys maps the keys of the notification rules menu to the rules they
+// toggle, in display order.
+var ruleKeys = []struct {
+	key   string
+	rule  notify.Rule
+	label string
+}{
+	{"i", notify.RuleInputOnly, "only needs input"},
+	{"q", notify.RuleQuietHours, "quiet hours"},
+	{"v", notify.RuleSkipVisible, "skip visible"},
+	{"e", notify.RuleEscalate, "repeat if waiting"},
+}
+
+// updateRules toggles notification rules from the status-line menu.
+func (a App) updateRules(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	if keyMsg.Type == tea.KeyEscape || keyMsg.String() == "A" {
+		a.mode = modeNormal
+		return a, nil
+	}
+	for _, rk := range ruleKeys {
+		if keyMsg.String() != rk.key {
+			continue
+		}
+		if _, err := a.manager.ToggleRule(rk.rule); err != nil {
+			a.err = err.Error()
+		} else {
+			a.err = ""
+		}
+	}
+	return a, nil
+}
+
+// renderRules shows the notification rules menu with each rule's state.
+func (a App) renderRules() string {
+	hintStyle := statusBarStyle.PaddingTop(0)
+	rules := a.manager.Rules
+	lines := []string{hintStyle.Render("notification rules · esc close")}
+	for _, rk := range ruleKeys {
+		state := "off"
+		if rules.Enabled(rk.rule) {
+			state = "on"
+			switch rk.rule {
+			case notify.RuleQuietHours:
+				state = rules.QuietHours.Start + "-" + rules.QuietHours.End
+			case notify.RuleEscalate:
+				state = fmt.Sprintf("every %dm", rules.EscalateAfter)
+			}
+		}
+		lines = append(lines, hintStyle.Render(fmt.Sprintf("%s  %-18s %s", rk.key, rk.label, state)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// updateExport picks the export format from the status-line menu and
+// starts the export.
+func (a App) updateExport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	var format string
+	switch keyMsg.String() {
+	case "m":
+		format = "markdown"
+	case "h":
+		format = "html"
+	case "esc", "E":
+		a.mode = modeNormal
+		return a, nil
+	default:
+		return a, nil
+	}
+	a.mode = modeNormal
+	s := a.manager.State.FindByID(a.exportID)
+	if s == nil {
+		a.err = "session not found"
+		return a, nil
+	}
+	a.exporting = true
+	a.err = ""
+	a.info = "exporting " + s.DisplayName() + "..."
+	return a, exportSession(a.manager, *s, format)
+}
+
+// syncPreview points the preview split at the session under the cursor.
+// Project headers leave the current preview in place.
+func (a *App) syncPreview() {
+	if !a.previewing {
+		return
+	}
+	sel := a.sidebar.Selected()
+	if sel == nil {
+		return
+	}
+	if err := a.manager.ShowPreview(sel.ID); err != nil {
+		a.err = err.Error()
+	}
+}
+
+// closePreview leaves preview mode and removes the split.
+func (a *App) closePreview() {
+	if !a.previewing {
+		return
+	}
+	a.previewing = false
+	a.manager.HidePreview()
+}
+
 func (a App) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -346,6 +827,41 @@ func (a App) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// updateTags edits the tags of the selected session inline. Tags are
+// separated by spaces or commas; an empty line clears them.
+func (a App) updateTags(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEscape:
+			a.mode = modeNormal
+			return a, nil
+		case tea.KeyEnter:
+			a.mode = modeNormal
+			if err := a.manager.SetTags(a.tagSessionID, session.ParseTags(a.tagText)); err != nil {
+				a.err = err.Error()
+			} else {
+				a.err = ""
+			}
+			a.sidebar.SetSessions(a.manager.ListSessions())
+			a.sidebar.SetCursorToSession(a.tagSessionID)
+			return a, nil
+		case tea.KeyBackspace:
+			if r := []rune(a.tagText); len(r) > 0 {
+				a.tagText = string(r[:len(r)-1])
+			}
+			return a, nil
+		case tea.KeySpace:
+			a.tagText += " "
+			return a, nil
+		case tea.KeyRunes:
+			a.tagText += string(msg.Runes)
+			return a, nil
+		}
+	}
+	return a, nil
+}
+
 func (a App) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
 	result, cmd := a.prompt.Update(msg)
 	if result != nil {
@@ -466,6 +982,72 @@ func (a App) handleNewTerminal() (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// handleStartServices starts the services declared by the project under
+// the cursor that aren't running yet.
+func (a App) handleStartServices() (tea.Model, tea.Cmd) {
+	project, dir := a.sidebar.CurrentProjectInfo()
+	if dir == "" {
+		a.err = "no project context for services"
+		return a, nil
+	}
+
+	if n, err := a.manager.StartServices(project, dir); err != nil {
+		a.err = err.Error()
+	} else if n == 0 {
+		a.err = ""
+		a.info = "services already running"
+	} else {
+		a.err = ""
+	}
+	a.sidebar.SetSessions(a.manager.ListSessions())
+	return a, nil
+}
+
+func (a App) handleNewPairedTerminal() (tea.Model, tea.Cmd) {
+	sel := a.sidebar.Selected()
+	if sel == nil || sel.Type == session.TypeTerminal {
+		a.err = "select a Claude session to pair with"
+		return a, nil
+	}
+
+	if _, err := a.manager.CreatePairedTerminal(sel.ID); err != nil {
+		a.err = err.Error()
+	} else {
+		a.err = ""
+	}
+	a.sidebar.SetSessions(a.manager.ListSessions())
+	a.sidebar.SetActive(a.manager.State.LastActiveSession)
+	return a, nil
+}
+
+// handleTogglePairing pairs the selected terminal with the Claude session
+// of its project used last, or unpairs it if it is paired.
+func (a App) handleTogglePairing() (tea.Model, tea.Cmd) {
+	sel := a.sidebar.Selected()
+	if sel == nil || sel.Type != session.TypeTerminal {
+		a.err = "select a terminal to pair or unpair"
+		return a, nil
+	}
+
+	parentID, msg := "", "unpaired"
+	if sel.ParentID == "" {
+		parent := a.manager.PairCandidate(sel.ID)
+		if parent == nil {
+			a.err = "no Claude session in " + sel.Project + " to pair with"
+			return a, nil
+		}
+		parentID, msg = parent.ID, "paired with "+parent.DisplayName()
+	}
+	if err := a.manager.SetParent(sel.ID, parentID); err != nil {
+		a.err = err.Error()
+	} else {
+		a.err = ""
+		a.info = msg
+	}
+	a.sidebar.SetSessions(a.manager.ListSessions())
+	return a, nil
+}
+
 func (a App) launchWorktreePopup(project, repoRoot string) (tea.Model, tea.Cmd) {
 	if a.waitingPopup {
 		return a, nil
@@ -511,6 +1093,204 @@ func (a App) launchWorktreePopup(project, repoRoot string) (tea.Model, tea.Cmd)
 	return a, checkPopupResult(resultPath)
 }
 
+func (a App) launchPalette() (tea.Model, tea.Cmd) {
+	if a.waitingPopup {
+		return a, nil
+	}
+
+	if !htmux.TmuxSupportsPopup() {
+		a.err = "command palette requires tmux >= 3.2"
+		return a, nil
+	}
+
+	resultPath := htmux.PopupResultPath()
+	os.Remove(resultPath)
+
+	executable, err := os.Executable()
+	if err != nil {
+		a.err = "failed to find executable"
+		return a, nil
+	}
+
+	popupArgs := []string{
+		executable, "popup-palette",
+		"--result-path", resultPath,
+	}
+	if a.profileName != "" {
+		popupArgs = append(popupArgs, "--profile", a.profileName)
+	}
+
+	opts := htmux.PopupOpts{
+		Title:  "Command Palette",
+		Width:  72,
+		Height: 20,
+	}
+
+	if err := htmux.ShowPopup(opts, popupArgs...); err != nil {
+		a.err = "failed to open popup"
+		return a, nil
+	}
+
+	a.waitingPopup = true
+	a.err = ""
+	return a, checkPopupResult(resultPath)
+}
+
+// launchGrep opens the output search popup; the chosen match comes back
+// as a "grep" result.
+func (a App) launchGrep() (tea.Model, tea.Cmd) {
+	if a.waitingPopup {
+		return a, nil
+	}
+
+	if !htmux.TmuxSupportsPopup() {
+		a.err = "output search requires tmux >= 3.2"
+		return a, nil
+	}
+
+	resultPath := htmux.PopupResultPath()
+	os.Remove(resultPath)
+
+	executable, err := os.Executable()
+	if err != nil {
+		a.err = "failed to find executable"
+		return a, nil
+	}
+
+	popupArgs := []string{
+		executable, "popup-grep",
+		"--result-path", resultPath,
+	}
+	if a.profileName != "" {
+		popupArgs = append(popupArgs, "--profile", a.profileName)
+	}
+
+	opts := htmux.PopupOpts{
+		Title:  "Search Output",
+		Width:  100,
+		Height: 22,
+	}
+
+	if err := htmux.ShowPopup(opts, popupArgs...); err != nil {
+		a.err = "failed to open popup"
+		return a, nil
+	}
+
+	a.waitingPopup = true
+	a.err = ""
+	return a, checkPopupResult(resultPath)
+}
+
+// jumpToMatch switches to the session of an output search hit and scrolls
+// its pane to the hit in copy mode.
+func (a App) jumpToMatch(msg popupResultMsg) (tea.Model, tea.Cmd) {
+	a.closePreview()
+	if err := a.manager.SwitchTo(msg.SessionID); err != nil {
+		a.err = err.Error()
+		return a, nil
+	}
+	a.err = ""
+	if msg.Match != nil {
+		if err := a.manager.JumpToMatch(msg.SessionID, *msg.Match); err != nil {
+			a.err = err.Error()
+		}
+	}
+	a.sidebar.SetFilter("")
+	a.sidebar.SetSessions(a.manager.ListSessions())
+	a.sidebar.SetActive(a.manager.State.LastActiveSession)
+	return a, nil
+}
+
+// showPorts opens a popup listing the selected terminal's listening ports.
+// The popup opens or copies URLs itself, so there is no result to wait for.
+func (a *App) showPorts() {
+	sel := a.sidebar.Selected()
+	if sel == nil || sel.Type != session.TypeTerminal {
+		a.err = "select a terminal to list its ports"
+		return
+	}
+	if len(sel.Listeners) == 0 {
+		a.err = "not listening on any ports"
+		return
+	}
+	if !htmux.TmuxSupportsPopup() {
+		a.err = "ports popup requires tmux >= 3.2"
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		a.err = "failed to find executable"
+		return
+	}
+	popupArgs := []string{executable, "popup-ports", "--session", sel.ID}
+	if a.profileName != "" {
+		popupArgs = append(popupArgs, "--profile", a.profileName)
+	}
+	opts := htmux.PopupOpts{
+		Title:  "Ports",
+		Width:  60,
+		Height: len(sel.Listeners) + 8,
+	}
+	if err := htmux.ShowPopup(opts, popupArgs...); err != nil {
+		a.err = "failed to open popup"
+		return
+	}
+	a.err = ""
+}
+
+// showOverview opens a popup listing the sessions needing attention in
+// every profile. The popup switches sessions, or profiles, itself.
+func (a *App) showOverview() {
+	if !htmux.TmuxSupportsPopup() {
+		a.err = "overview popup requires tmux >= 3.2"
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		a.err = "failed to find executable"
+		return
+	}
+	popupArgs := []string{executable, "popup-overview"}
+	if a.profileName != "" {
+		popupArgs = append(popupArgs, "--profile", a.profileName)
+	}
+	opts := htmux.PopupOpts{
+		Title:  "Overview",
+		Width:  80,
+		Height: 20,
+	}
+	if err := htmux.ShowPopup(opts, popupArgs...); err != nil {
+		a.err = "failed to open popup"
+		return
+	}
+	a.err = ""
+}
+
+// runPaletteResult executes a command palette selection: switch to a
+// session, jump to a project header, or replay an action's key binding.
+func (a App) runPaletteResult(msg popupResultMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.SessionID != "":
+		a.closePreview()
+		if err := a.manager.SwitchTo(msg.SessionID); err != nil {
+			a.err = err.Error()
+			return a, nil
+		}
+		a.err = ""
+		a.sidebar.SetFilter("")
+		a.sidebar.SetSessions(a.manager.ListSessions())
+		a.sidebar.SetActive(a.manager.State.LastActiveSession)
+	case msg.Project != "":
+		a.sidebar.SetFilter("")
+		a.sidebar.SetCursorToProject(msg.Project)
+	case msg.Action != "":
+		if act, ok := findPaletteAction(msg.Action); ok {
+			return a.updateNormal(keyMsgFor(act.Binding))
+		}
+	}
+	return a, nil
+}
+
 func (a App) renderHelp() string {
 	hintStyle := statusBarStyle.PaddingTop(0)
 	header := statusBarStyle.Render("shortcuts")
@@ -521,12 +1301,37 @@ func (a App) renderHelp() string {
 		hintStyle.Render("space  collapse"),
 		hintStyle.Render("J/K    move up/down"),
 		hintStyle.Render("/      search"),
+		hintStyle.Render("v      cycle view"),
+		hintStyle.Render("^p     command palette"),
+		hintStyle.Render("o      previous session"),
+		hintStyle.Render("i      next needing input"),
+		hintStyle.Render("u      next done"),
+		hintStyle.Render("O      all profiles"),
+		hintStyle.Render("p      preview"),
+		hintStyle.Render("I      details & cost"),
+		hintStyle.Render("P      listening ports"),
+		hintStyle.Render("g      search output"),
+		hintStyle.Render("L      log output"),
+		hintStyle.Render("E      export (markdown/html)"),
+		hintStyle.Render("| -    split viewport"),
+		hintStyle.Render("tab    next slot"),
+		hintStyle.Render("X      close slot"),
 		hintStyle.Render("n      new session"),
 		hintStyle.Render("N      new project"),
 		hintStyle.Render("w      worktree"),
 		hintStyle.Render("t      terminal"),
+		hintStyle.Render("T      paired terminal"),
+		hintStyle.Render("b      toggle paired"),
+		hintStyle.Render("a      pair/unpair terminal"),
+		hintStyle.Render("S      start services"),
+		hintStyle.Render("s/r    stop/restart terminal"),
+		hintStyle.Render("C      clear & rerun"),
 		hintStyle.Render("d      delete (confirms)"),
+		hintStyle.Render("D      delete filtered"),
+		hintStyle.Render("#      edit tags"),
 		hintStyle.Render("m      mute"),
+		hintStyle.Render("M      mute session/project"),
+		hintStyle.Render("A      notification rules"),
 		hintStyle.Render("R      reload sidebar"),
 		hintStyle.Render("q      quit"),
 		hintStyle.Render("?      close"),
@@ -539,6 +1344,9 @@ func (a App) View() string {
 	if a.profileName != "" {
 		titleText = "🐕 herd (" + a.profileName + ")"
 	}
+	if v := a.sidebar.CurrentView(); v != viewProject {
+		titleText += " · " + v.label()
+	}
 	if a.manager.Notifier != nil && a.manager.Notifier.IsMuted() {
 		titleText += " 🔇"
 	}
@@ -571,12 +1379,29 @@ func (a App) View() string {
 			}
 			name = truncate(name, maxName)
 			statusLine = deleteConfirmStyle.Render("delete \""+name+"\"? y/n")
+			if n := len(a.manager.State.PairedTerminals(a.pendingDelete.ID)); n > 0 {
+				statusLine += "\n" + statusBarStyle.PaddingTop(0).Render(fmt.Sprintf("a  also kill %d paired", n))
+			}
+		} else if a.pendingBulk != nil {
+			statusLine = deleteConfirmStyle.Render(fmt.Sprintf("delete %d filtered? y/n", len(a.pendingBulk)))
 		} else if a.mode == modeSearch {
 			statusLine = searchStyle.Render("/ " + a.searchText + "█")
+		} else if a.mode == modeTags {
+			statusLine = searchStyle.Render("# "+a.tagText+"█") + "\n" + statusBarStyle.PaddingTop(0).Render("enter save · esc cancel")
+		} else if a.mode == modeRules {
+			statusLine = a.renderRules()
+		} else if a.mode == modeExport {
+			statusLine = searchStyle.Render("export as") + "\n" + statusBarStyle.PaddingTop(0).Render("m markdown · h html · esc cancel")
 		} else if a.showHelp {
 			statusLine = a.renderHelp()
+		} else if a.showDetail {
+			statusLine = a.renderDetail()
+		} else if a.previewing {
+			statusLine = searchStyle.Render("preview") + "  " + statusBarStyle.PaddingTop(0).Render("p/esc close")
 		} else if a.sidebar.Filter() != "" {
 			statusLine = searchStyle.Render("/ "+a.sidebar.Filter()) + "  " + statusBarStyle.PaddingTop(0).Render("? shortcuts")
+		} else if active, total := a.manager.SlotInfo(); total > 1 {
+			statusLine = statusBarStyle.Render(fmt.Sprintf("slot %d/%d · ? shortcuts", active, total))
 		} else {
 			statusLine = statusBarStyle.Render("? shortcuts")
 		}
@@ -586,10 +1411,14 @@ func (a App) View() string {
 		}
 		if a.err != "" {
 			statusLine = errStyle.Render("err: "+a.err) + "\n" + statusLine
+		} else if a.info != "" {
+			statusLine = infoStyle.Render(a.info) + "\n" + statusLine
 		}
 	} else {
 		if a.err != "" {
 			statusLine = errBlurredStyle.Render("err: "+a.err) + "\n" + statusBarBlurredStyle.Render("← ctrl-h · ctrl-l →")
+		} else if a.info != "" {
+			statusLine = infoBlurredStyle.Render(a.info) + "\n" + statusBarBlurredStyle.Render("← ctrl-h · ctrl-l →")
 		} else {
 			statusLine = statusBarBlurredStyle.Render("← ctrl-h · ctrl-l →")
 		}
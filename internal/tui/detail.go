@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+	"github.com/allenan/herd/internal/usage"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderDetail describes the session under the cursor: where it runs, its
+// tags and what it has cost so far, next to its project and profile totals.
+// On a group header it shows the totals only.
+func (a App) renderDetail() string {
+	hintStyle := statusBarStyle.PaddingTop(0)
+	row := func(label, value string) string {
+		return hintStyle.Render(fmt.Sprintf("%-8s %s", label, value))
+	}
+
+	var project string
+	var lines []string
+	if sel := a.sidebar.Selected(); sel != nil {
+		project = sel.Project
+		lines = append(lines, statusBarStyle.Render(sel.DisplayName()))
+		where := sel.Project
+		if sel.WorktreeBranch != "" {
+			where += " · " + sel.WorktreeBranch
+		}
+		lines = append(lines, row("project", where))
+		lines = append(lines, row("dir", shortenHome(sel.Dir)))
+		lines = append(lines, row("status", string(sel.Status)))
+		if len(sel.Tags) > 0 {
+			lines = append(lines, row("tags", tagChips(sel.Tags)))
+		}
+		for i, l := range sel.Listeners {
+			label := ""
+			if i == 0 {
+				label = "ports"
+			}
+			lines = append(lines, row(label, fmt.Sprintf("%s %s", l.URL(), l.Process)))
+		}
+		if sel.Port > 0 {
+			lines = append(lines, row("PORT", fmt.Sprint(sel.Port)))
+		}
+		if sel.Logging {
+			lines = append(lines, row("output", "logged"))
+		}
+		if sel.PortConflict != nil {
+			lines = append(lines, row("conflict", sel.PortConflict.String()))
+		}
+		if sel.Type == session.TypeClaude {
+			u := a.manager.CachedUsage(sel.ID)
+			lines = append(lines,
+				row("tokens", fmt.Sprintf("in %s · out %s", formatTokens(u.InputTokens), formatTokens(u.OutputTokens))),
+				row("cache", fmt.Sprintf("read %s · write %s", formatTokens(u.CacheReadTokens), formatTokens(u.CacheCreationTokens))),
+				row("cost", u.CostLabel()),
+				row("context", fmt.Sprintf("%d%% used", sel.ContextPercent)),
+			)
+		}
+	} else {
+		project, _ = a.sidebar.CurrentProjectInfo()
+		lines = append(lines, statusBarStyle.Render("totals"))
+	}
+
+	var projectCost, allCost usage.Usage
+	for i := range a.manager.State.Sessions {
+		s := &a.manager.State.Sessions[i]
+		if s.Type != session.TypeClaude {
+			continue
+		}
+		u := a.manager.CachedUsage(s.ID)
+		allCost.Add(u)
+		if s.Project == project {
+			projectCost.Add(u)
+		}
+	}
+	if project != "" {
+		lines = append(lines, row(truncate(project, 8), projectCost.CostLabel()))
+	}
+	lines = append(lines, row("profile", allCost.CostLabel()))
+	lines = append(lines, hintStyle.Render("I/esc close"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// formatTokens abbreviates a token count: 950, 12.3k, 1.2M.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// shortenHome replaces the home directory prefix with ~.
+func shortenHome(dir string) string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" && strings.HasPrefix(dir, home) {
+		return "~" + strings.TrimPrefix(dir, home)
+	}
+	return dir
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy scoring weights. Consecutive and word-start matches dominate so
+// "fab" ranks "Fix auth bug" above "prefab".
+const (
+	fuzzyMatchScore       = 1
+	fuzzyConsecutiveBonus = 5
+	fuzzyWordStartBonus   = 8
+	fuzzyPrefixBonus      = 10
+	fuzzyGapPenalty       = 1
+)
+
+// fuzzyScore matches pattern against text as a case-insensitive
+// subsequence. It returns the match score and whether every pattern rune
+// was found. An empty pattern matches everything with score 0.
+func fuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	tl := []rune(strings.ToLower(text))
+
+	score := 0
+	pi := 0
+	prev := -2
+	for ti := 0; ti < len(tl) && pi < len(p); ti++ {
+		if tl[ti] != p[pi] {
+			continue
+		}
+		score += fuzzyMatchScore
+		switch {
+		case ti == 0:
+			score += fuzzyPrefixBonus
+		case isWordStart(t, ti):
+			score += fuzzyWordStartBonus
+		}
+		if ti == prev+1 {
+			score += fuzzyConsecutiveBonus
+		} else if prev >= 0 {
+			score -= fuzzyGapPenalty
+		}
+		prev = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
+
+// isWordStart reports whether t[i] begins a word: it follows a separator
+// or is an upper-case letter after a lower-case one.
+func isWordStart(t []rune, i int) bool {
+	prev := t[i-1]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsUpper(t[i]) && unicode.IsLower(prev)
+}
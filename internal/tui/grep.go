@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	htmux "github.com/allenan/herd/internal/tmux"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxVisibleGrepMatches = 14
+
+// grepDoneMsg carries the results of a search run in the background.
+type grepDoneMsg struct {
+	pattern string
+	matches []htmux.Match
+	err     error
+}
+
+// GrepModel is the Bubble Tea model for the output search popup: a
+// pattern is searched across all sessions' logs and scrollback, and the
+// chosen match is jumped to by the sidebar.
+type GrepModel struct {
+	input      textinput.Model
+	search     func(pattern string) ([]htmux.Match, error)
+	searched   string // pattern of the current matches
+	searching  bool
+	matches    []htmux.Match
+	selected   int
+	scrollOff  int
+	width      int
+	resultPath string
+	err        string
+}
+
+// NewGrepModel creates a search popup. search runs a pattern against the
+// sessions' output; the caller provides it since that goes through tmux.
+func NewGrepModel(search func(string) ([]htmux.Match, error), resultPath string) GrepModel {
+	ti := textinput.New()
+	ti.Placeholder = "regular expression"
+	ti.CharLimit = 256
+	ti.Width = 60
+	ti.Prompt = "> "
+	ti.Focus()
+
+	return GrepModel{
+		input:      ti,
+		search:     search,
+		resultPath: resultPath,
+	}
+}
+
+func (m GrepModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m GrepModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		if m.width > 8 {
+			m.input.Width = m.width - 8
+		}
+		return m, nil
+
+	case grepDoneMsg:
+		m.searching = false
+		m.searched = msg.pattern
+		m.matches = msg.matches
+		m.err = ""
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		// Most recent output first, like the bottom of a terminal
+		m.selected = max(len(m.matches)-1, 0)
+		m.scrollOff = max(len(m.matches)-maxVisibleGrepMatches, 0)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, tea.Quit
+
+		case "enter":
+			pattern := m.input.Value()
+			if pattern == "" || m.searching {
+				return m, nil
+			}
+			if pattern != m.searched {
+				m.searching = true
+				search := m.search
+				return m, func() tea.Msg {
+					matches, err := search(pattern)
+					return grepDoneMsg{pattern: pattern, matches: matches, err: err}
+				}
+			}
+			if len(m.matches) == 0 {
+				return m, nil
+			}
+			if err := m.writeResult(m.matches[m.selected]); err != nil {
+				m.err = "failed to write result"
+				return m, nil
+			}
+			return m, tea.Quit
+
+		case "up", "ctrl+p", "ctrl+k":
+			if m.selected > 0 {
+				m.selected--
+				if m.selected < m.scrollOff {
+					m.scrollOff = m.selected
+				}
+			}
+			return m, nil
+
+		case "down", "ctrl+n", "ctrl+j":
+			if m.selected < len(m.matches)-1 {
+				m.selected++
+				if m.selected >= m.scrollOff+maxVisibleGrepMatches {
+					m.scrollOff = m.selected - maxVisibleGrepMatches + 1
+				}
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *GrepModel) writeResult(match htmux.Match) error {
+	result := PopupResult{Mode: "grep", SessionID: match.SessionID, Match: &match}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	tmp := m.resultPath + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(m.resultPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.resultPath)
+}
+
+func (m GrepModel) View() string {
+	w := m.width
+	if w <= 0 {
+		w = 90
+	}
+	innerW := w - 6
+
+	var lines []string
+	end := min(m.scrollOff+maxVisibleGrepMatches, len(m.matches))
+	for i := m.scrollOff; i < end; i++ {
+		match := m.matches[i]
+		marker := "  "
+		style := popupSuggestionStyle
+		if i == m.selected {
+			marker = "> "
+			style = popupSuggestionSelectedStyle
+		}
+		where := truncate(match.Session, 16)
+		if match.Source == "log" {
+			where += " (log)"
+		}
+		text := truncate(strings.TrimSpace(match.Text), max(innerW-len([]rune(where))-4, 8))
+		lines = append(lines, marker+paletteKindStyle.Render(where)+" "+style.Render(text))
+	}
+	switch {
+	case m.searching:
+		lines = append(lines, "  "+popupHintStyle.Render("searching…"))
+	case m.searched != "" && len(m.matches) == 0:
+		lines = append(lines, "  "+popupHintStyle.Render("no matches"))
+	}
+	for len(lines) < maxVisibleGrepMatches {
+		lines = append(lines, "")
+	}
+
+	status := ""
+	if m.searched != "" && !m.searching {
+		status = fmt.Sprintf("%d matches", len(m.matches))
+	}
+
+	var sections []string
+	sections = append(sections, "")
+	sections = append(sections, "  "+m.input.View())
+	sections = append(sections, "")
+	sections = append(sections, strings.Join(lines, "\n"))
+	if m.err != "" {
+		sections = append(sections, "  "+popupErrStyle.Render(m.err))
+	} else {
+		sections = append(sections, "  "+popupHintStyle.Render(status))
+	}
+	sections = append(sections, "  "+popupHintStyle.Render("enter search, then ↑/↓ select · enter jump · esc cancel"))
+	return strings.Join(sections, "\n")
+}
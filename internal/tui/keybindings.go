@@ -1,6 +1,9 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 type keyMap struct {
 	Up         key.Binding
@@ -11,11 +14,38 @@ type keyMap struct {
 	NewProject key.Binding
 	Worktree   key.Binding
 	Terminal   key.Binding
+	PairedTerm key.Binding
+	TogglePair key.Binding
+	AttachTerm key.Binding
+	Services   key.Binding
+	Stop       key.Binding
+	Restart    key.Binding
+	Rerun      key.Binding
+	Grep       key.Binding
+	ToggleLog  key.Binding
+	Export     key.Binding
 	Delete     key.Binding
+	BulkDelete key.Binding
+	EditTags   key.Binding
 	Search     key.Binding
+	CycleView  key.Binding
+	Palette    key.Binding
+	JumpLast   key.Binding
+	JumpInput  key.Binding
+	JumpDone   key.Binding
 	MoveUp     key.Binding
 	MoveDown   key.Binding
+	Preview    key.Binding
+	Detail     key.Binding
+	Ports      key.Binding
+	Overview   key.Binding
+	SplitRight key.Binding
+	SplitDown  key.Binding
+	NextSlot   key.Binding
+	CloseSlot  key.Binding
 	Mute       key.Binding
+	MuteItem   key.Binding
+	Rules      key.Binding
 	Reload     key.Binding
 	Quit       key.Binding
 	Help       key.Binding
@@ -54,14 +84,82 @@ var keys = keyMap{
 		key.WithKeys("t"),
 		key.WithHelp("t", "terminal"),
 	),
+	PairedTerm: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "paired terminal"),
+	),
+	TogglePair: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "toggle paired terminal"),
+	),
+	AttachTerm: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "pair/unpair terminal"),
+	),
+	Services: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "start project services"),
+	),
+	Stop: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "stop terminal command"),
+	),
+	Restart: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "restart terminal command"),
+	),
+	Rerun: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "clear & rerun terminal command"),
+	),
+	Grep: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "search all sessions' output"),
+	),
+	ToggleLog: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "toggle output logging"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "export session as Markdown or HTML"),
+	),
 	Delete: key.NewBinding(
 		key.WithKeys("d"),
 		key.WithHelp("d", "delete"),
 	),
+	BulkDelete: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "delete all filtered"),
+	),
+	EditTags: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "edit tags"),
+	),
 	Search: key.NewBinding(
 		key.WithKeys("/"),
 		key.WithHelp("/", "search"),
 	),
+	CycleView: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "cycle sidebar view"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "command palette"),
+	),
+	JumpLast: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "previous session"),
+	),
+	JumpInput: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "next needing input"),
+	),
+	JumpDone: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "next done/plan ready"),
+	),
 	MoveUp: key.NewBinding(
 		key.WithKeys("K"),
 		key.WithHelp("K", "move up"),
@@ -70,10 +168,50 @@ var keys = keyMap{
 		key.WithKeys("J"),
 		key.WithHelp("J", "move down"),
 	),
+	Preview: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "preview"),
+	),
+	Detail: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "session details"),
+	),
+	Ports: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "listening ports"),
+	),
+	Overview: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "all profiles overview"),
+	),
+	SplitRight: key.NewBinding(
+		key.WithKeys("|"),
+		key.WithHelp("|", "split side by side"),
+	),
+	SplitDown: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "split stacked"),
+	),
+	NextSlot: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next slot"),
+	),
+	CloseSlot: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "close slot"),
+	),
 	Mute: key.NewBinding(
 		key.WithKeys("m"),
 		key.WithHelp("m", "mute"),
 	),
+	MuteItem: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "mute session/project"),
+	),
+	Rules: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "notification rules"),
+	),
 	Reload: key.NewBinding(
 		key.WithKeys("R"),
 		key.WithHelp("R", "reload sidebar"),
@@ -87,3 +225,86 @@ var keys = keyMap{
 		key.WithHelp("?", "shortcuts"),
 	),
 }
+
+// paletteAction is a sidebar action offered by the command palette.
+type paletteAction struct {
+	Name    string
+	Binding key.Binding
+}
+
+// paletteActions lists the sidebar actions the command palette can run.
+// Plain navigation keys are left out since the palette replaces them.
+func paletteActions() []paletteAction {
+	return []paletteAction{
+		{"new", keys.New},
+		{"new_project", keys.NewProject},
+		{"worktree", keys.Worktree},
+		{"terminal", keys.Terminal},
+		{"paired_terminal", keys.PairedTerm},
+		{"toggle_paired", keys.TogglePair},
+		{"pair_terminal", keys.AttachTerm},
+		{"start_services", keys.Services},
+		{"stop", keys.Stop},
+		{"restart", keys.Restart},
+		{"clear_rerun", keys.Rerun},
+		{"search_output", keys.Grep},
+		{"toggle_log", keys.ToggleLog},
+		{"export", keys.Export},
+		{"delete", keys.Delete},
+		{"delete_filtered", keys.BulkDelete},
+		{"edit_tags", keys.EditTags},
+		{"search", keys.Search},
+		{"cycle_view", keys.CycleView},
+		{"jump_last", keys.JumpLast},
+		{"jump_input", keys.JumpInput},
+		{"jump_done", keys.JumpDone},
+		{"move_up", keys.MoveUp},
+		{"move_down", keys.MoveDown},
+		{"collapse", keys.Space},
+		{"preview", keys.Preview},
+		{"details", keys.Detail},
+		{"ports", keys.Ports},
+		{"overview", keys.Overview},
+		{"split_right", keys.SplitRight},
+		{"split_down", keys.SplitDown},
+		{"next_slot", keys.NextSlot},
+		{"close_slot", keys.CloseSlot},
+		{"mute", keys.Mute},
+		{"mute_item", keys.MuteItem},
+		{"notification_rules", keys.Rules},
+		{"reload", keys.Reload},
+		{"quit", keys.Quit},
+		{"help", keys.Help},
+	}
+}
+
+// findPaletteAction looks up a palette action by name.
+func findPaletteAction(name string) (paletteAction, bool) {
+	for _, act := range paletteActions() {
+		if act.Name == name {
+			return act, true
+		}
+	}
+	return paletteAction{}, false
+}
+
+// keyMsgFor builds the key message that triggers a binding, so palette
+// selections run through the same code path as the keyboard.
+func keyMsgFor(b key.Binding) tea.KeyMsg {
+	k := b.Keys()[0]
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "ctrl+p":
+		return tea.KeyMsg{Type: tea.KeyCtrlP}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allenan/herd/internal/overview"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overviewRefresh is how often the overview re-reads the profiles' state.
+const overviewRefresh = 2 * time.Second
+
+type overviewLoadedMsg struct {
+	items []overview.Item
+	all   bool // the list it was loaded for
+	err   error
+}
+
+type overviewTickMsg struct{}
+
+// OverviewModel is the Bubble Tea model for the cross-profile overview:
+// the sessions needing attention in every running profile. The chosen
+// session is read with Chosen after the program exits.
+type OverviewModel struct {
+	collect   func(all bool) ([]overview.Item, error)
+	current   string // profile the overview runs in, "" = default
+	items     []overview.Item
+	all       bool // list every session, not just those needing attention
+	loaded    bool
+	selected  int
+	scrollOff int
+	height    int
+	width     int
+	chosen    *overview.Item
+	err       string
+}
+
+// NewOverviewModel creates an overview reading sessions through collect.
+// current is the profile of the terminal it runs in, if any, to tell its
+// sessions apart.
+func NewOverviewModel(collect func(all bool) ([]overview.Item, error), current string) OverviewModel {
+	return OverviewModel{collect: collect, current: current}
+}
+
+// Chosen returns the session picked with Enter.
+func (m OverviewModel) Chosen() (overview.Item, bool) {
+	if m.chosen == nil {
+		return overview.Item{}, false
+	}
+	return *m.chosen, true
+}
+
+func (m OverviewModel) load() tea.Cmd {
+	collect, all := m.collect, m.all
+	return func() tea.Msg {
+		items, err := collect(all)
+		return overviewLoadedMsg{items: items, all: all, err: err}
+	}
+}
+
+func overviewTick() tea.Cmd {
+	return tea.Tick(overviewRefresh, func(time.Time) tea.Msg { return overviewTickMsg{} })
+}
+
+func (m OverviewModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), overviewTick())
+}
+
+func (m OverviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case overviewLoadedMsg:
+		if msg.all != m.all {
+			// Loaded before a toggled the list; the new one is on its way
+			return m, nil
+		}
+		// Keep the cursor on the same session across refreshes
+		var selectedID string
+		if m.selected < len(m.items) {
+			selectedID = m.items[m.selected].Session.ID
+		}
+		m.items = msg.items
+		m.loaded = true
+		m.err = ""
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		m.selected = min(m.selected, max(len(m.items)-1, 0))
+		for i, it := range m.items {
+			if it.Session.ID == selectedID {
+				m.selected = i
+			}
+		}
+		m.clampScroll()
+		return m, nil
+
+	case overviewTickMsg:
+		// The only place the tick is rescheduled, so reloads on a keypress
+		// don't start another poller
+		return m, tea.Batch(m.load(), overviewTick())
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+				m.clampScroll()
+			}
+		case "down", "j":
+			if m.selected < len(m.items)-1 {
+				m.selected++
+				m.clampScroll()
+			}
+		case "a":
+			m.all = !m.all
+			return m, m.load()
+		case "enter":
+			if m.selected < len(m.items) {
+				it := m.items[m.selected]
+				m.chosen = &it
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+// visibleRows is how many sessions fit between the header and the hints.
+func (m OverviewModel) visibleRows() int {
+	if m.height <= 0 {
+		return 15
+	}
+	return max(m.height-6, 3)
+}
+
+func (m *OverviewModel) clampScroll() {
+	rows := m.visibleRows()
+	if m.selected < m.scrollOff {
+		m.scrollOff = m.selected
+	}
+	if m.selected >= m.scrollOff+rows {
+		m.scrollOff = m.selected - rows + 1
+	}
+}
+
+func (m OverviewModel) View() string {
+	title := "needing attention"
+	if m.all {
+		title = "all sessions"
+	}
+	lines := []string{"", "  " + popupLabelStyle.Render("Profiles · "+title), ""}
+
+	profileWidth := 0
+	for _, it := range m.items {
+		profileWidth = max(profileWidth, len(it.ProfileLabel()))
+	}
+	end := min(m.scrollOff+m.visibleRows(), len(m.items))
+	for i := m.scrollOff; i < end; i++ {
+		it := m.items[i]
+		s := &it.Session
+		marker := "  "
+		style := popupSuggestionStyle
+		if i == m.selected {
+			marker = "> "
+			style = popupSuggestionSelectedStyle
+		}
+		prof := fmt.Sprintf("%-*s", profileWidth, it.ProfileLabel())
+		if it.Profile == m.current {
+			prof = paletteKindStyle.Render(prof)
+		} else {
+			prof = projectHeaderStyle.Render(prof)
+		}
+		name := truncate(s.Project+"/"+s.DisplayName(), 40)
+		row := marker + prof + " " + statusIndicator(s, "*", "*") + " " + style.Render(name)
+		if note := timingNote(s); note != "" {
+			row += " " + timingNoteStyle.Render(note)
+		}
+		lines = append(lines, "  "+row)
+	}
+	if m.loaded && len(m.items) == 0 {
+		empty := "nothing needs attention"
+		if m.all {
+			empty = "no running profiles"
+		}
+		lines = append(lines, "  "+popupHintStyle.Render(empty))
+	}
+
+	lines = append(lines, "")
+	if m.err != "" {
+		lines = append(lines, "  "+popupErrStyle.Render(m.err))
+	}
+	lines = append(lines, "  "+popupHintStyle.Render("↑/↓ select · enter switch · a all/attention · esc close"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const maxVisiblePaletteItems = 12
+
+type paletteKind int
+
+const (
+	paletteKindSession paletteKind = iota
+	paletteKindProject
+	paletteKindAction
+)
+
+// paletteItem is one selectable row in the command palette.
+type paletteItem struct {
+	kind    paletteKind
+	label   string // what is shown and matched first
+	detail  string // secondary text, also matched
+	target  string // session ID, project name, or action name
+	recency int    // higher = used more recently
+	score   int    // set while filtering
+}
+
+// recencyBonus is added per recency step so recently used sessions win
+// ties against equally good matches.
+const recencyBonus = 3
+
+// buildPaletteItems lists sessions, projects and actions from state.
+func buildPaletteItems(state *session.State) []paletteItem {
+	var items []paletteItem
+
+	recent := recencyRanks(state)
+	seenProject := make(map[string]bool)
+	for _, s := range state.Sessions {
+		detail := s.Project
+		if s.WorktreeBranch != "" {
+			detail += " · " + s.WorktreeBranch
+		}
+		if len(s.Tags) > 0 {
+			detail += " · " + tagChips(s.Tags)
+		}
+		label := s.DisplayName()
+		if s.Type == session.TypeTerminal {
+			label = "$ " + label
+		}
+		items = append(items, paletteItem{
+			kind:    paletteKindSession,
+			label:   label,
+			detail:  detail,
+			target:  s.ID,
+			recency: recent[s.ID],
+		})
+		if !seenProject[s.Project] {
+			seenProject[s.Project] = true
+			items = append(items, paletteItem{
+				kind:   paletteKindProject,
+				label:  s.Project,
+				detail: "project",
+				target: s.Project,
+			})
+		}
+	}
+
+	for _, act := range paletteActions() {
+		items = append(items, paletteItem{
+			kind:   paletteKindAction,
+			label:  act.Binding.Help().Desc,
+			detail: act.Binding.Help().Key,
+			target: act.Name,
+		})
+	}
+	return items
+}
+
+// recencyRanks scores sessions by their position in the MRU history: the
+// most recent session gets the highest rank.
+func recencyRanks(state *session.State) map[string]int {
+	const tracked = 10
+	ranks := make(map[string]int)
+	for i, id := range state.History {
+		if i >= tracked {
+			break
+		}
+		ranks[id] = tracked - i
+	}
+	if _, ok := ranks[state.LastActiveSession]; !ok && state.LastActiveSession != "" {
+		ranks[state.LastActiveSession] = tracked
+	}
+	return ranks
+}
+
+// filterPaletteItems returns the items matching query, best first.
+func filterPaletteItems(items []paletteItem, query string) []paletteItem {
+	var out []paletteItem
+	for _, it := range items {
+		score, ok := fuzzyScore(query, it.label)
+		if detailScore, dok := fuzzyScore(query, it.detail); dok && (!ok || detailScore/2 > score) {
+			// Matching only the detail (project, branch, key) counts for less
+			score, ok = detailScore/2, true
+		}
+		if !ok {
+			continue
+		}
+		it.score = score + it.recency*recencyBonus
+		out = append(out, it)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].score != out[j].score {
+			return out[i].score > out[j].score
+		}
+		return out[i].kind < out[j].kind
+	})
+	return out
+}
+
+// PaletteModel is the Bubble Tea model for the command palette popup.
+type PaletteModel struct {
+	input      textinput.Model
+	items      []paletteItem
+	matches    []paletteItem
+	selected   int
+	scrollOff  int
+	width      int
+	height     int
+	resultPath string
+	err        string
+}
+
+// NewPaletteModel creates a command palette over the given state.
+func NewPaletteModel(state *session.State, resultPath string) PaletteModel {
+	ti := textinput.New()
+	ti.Placeholder = "sessions, projects, actions"
+	ti.CharLimit = 128
+	ti.Width = 60
+	ti.Prompt = "> "
+	ti.Focus()
+
+	items := buildPaletteItems(state)
+	return PaletteModel{
+		input:      ti,
+		items:      items,
+		matches:    filterPaletteItems(items, ""),
+		resultPath: resultPath,
+	}
+}
+
+func (m PaletteModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m PaletteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.width > 8 {
+			m.input.Width = m.width - 8
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, tea.Quit
+
+		case "enter":
+			if len(m.matches) == 0 {
+				return m, nil
+			}
+			if err := m.writeResult(m.matches[m.selected]); err != nil {
+				m.err = "failed to write result"
+				return m, nil
+			}
+			return m, tea.Quit
+
+		case "up", "ctrl+p", "ctrl+k":
+			if m.selected > 0 {
+				m.selected--
+				if m.selected < m.scrollOff {
+					m.scrollOff = m.selected
+				}
+			}
+			return m, nil
+
+		case "down", "ctrl+n", "ctrl+j":
+			if m.selected < len(m.matches)-1 {
+				m.selected++
+				if m.selected >= m.scrollOff+maxVisiblePaletteItems {
+					m.scrollOff = m.selected - maxVisiblePaletteItems + 1
+				}
+			}
+			return m, nil
+		}
+	}
+
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.matches = filterPaletteItems(m.items, m.input.Value())
+		m.selected = 0
+		m.scrollOff = 0
+	}
+	return m, cmd
+}
+
+func (m *PaletteModel) writeResult(item paletteItem) error {
+	result := PopupResult{Mode: "palette"}
+	switch item.kind {
+	case paletteKindSession:
+		result.SessionID = item.target
+	case paletteKindProject:
+		result.Project = item.target
+	case paletteKindAction:
+		result.Action = item.target
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	tmp := m.resultPath + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(m.resultPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.resultPath)
+}
+
+func (m PaletteModel) View() string {
+	w := m.width
+	if w <= 0 {
+		w = 70
+	}
+	innerW := w - 6
+
+	var lines []string
+	end := m.scrollOff + maxVisiblePaletteItems
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+	for i := m.scrollOff; i < end; i++ {
+		it := m.matches[i]
+		marker := "  "
+		style := popupSuggestionStyle
+		if i == m.selected {
+			marker = "> "
+			style = popupSuggestionSelectedStyle
+		}
+		kind := paletteKindLabel(it.kind)
+		label := truncate(it.label, max(innerW-len(kind)-len(it.detail)-4, 8))
+		lines = append(lines, marker+paletteKindStyle.Render(kind)+" "+style.Render(label)+"  "+popupHintStyle.Render(it.detail))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  "+popupHintStyle.Render("no matches"))
+	}
+	for len(lines) < maxVisiblePaletteItems {
+		lines = append(lines, "")
+	}
+
+	var sections []string
+	sections = append(sections, "")
+	sections = append(sections, "  "+m.input.View())
+	sections = append(sections, "")
+	sections = append(sections, strings.Join(lines, "\n"))
+	if m.err != "" {
+		sections = append(sections, "  "+popupErrStyle.Render(m.err))
+	}
+	sections = append(sections, "")
+	sections = append(sections, "  "+popupHintStyle.Render("↑/↓ select · enter run · esc cancel"))
+	return strings.Join(sections, "\n")
+}
+
+// paletteKindLabel returns a fixed-width tag for the item kind.
+func paletteKindLabel(k paletteKind) string {
+	switch k {
+	case paletteKindSession:
+		return "session"
+	case paletteKindProject:
+		return "project"
+	default:
+		return "action "
+	}
+}
+
+var paletteKindStyle = lipgloss.NewStyle().Foreground(colorSubtle)
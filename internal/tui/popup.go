@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/allenan/herd/internal/session"
+	htmux "github.com/allenan/herd/internal/tmux"
 	"github.com/allenan/herd/internal/worktree"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,9 +18,13 @@ const maxVisibleSuggestions = 8
 
 // PopupResult is the JSON structure written to the result file.
 type PopupResult struct {
-	Dir    string `json:"dir"`
-	Mode   string `json:"mode"`
-	Branch string `json:"branch,omitempty"`
+	Dir       string `json:"dir"`
+	Mode      string `json:"mode"`
+	Branch    string `json:"branch,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	Project   string `json:"project,omitempty"`
+	Action    string `json:"action,omitempty"`
+	Match     *htmux.Match `json:"match,omitempty"` // output search hit to jump to
 }
 
 // WriteCanceledResult writes a canceled result file if no result was already written.
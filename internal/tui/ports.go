@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PortsModel is the Bubble Tea model for the ports popup: the listening
+// sockets of a terminal session, each of which can be opened or copied.
+type PortsModel struct {
+	name      string
+	listeners []session.Listener
+	selected  int
+	copyText  func(text string) error // puts text on the clipboard
+	status    string
+	err       string
+	width     int
+}
+
+// NewPortsModel creates a ports popup for sess. copyText sets the clipboard;
+// the caller provides it since that goes through tmux.
+func NewPortsModel(sess *session.Session, copyText func(string) error) PortsModel {
+	return PortsModel{
+		name:      sess.DisplayName(),
+		listeners: sess.Listeners,
+		copyText:  copyText,
+	}
+}
+
+func (m PortsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PortsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.listeners)-1 {
+				m.selected++
+			}
+		case "enter", "o":
+			if len(m.listeners) == 0 {
+				return m, nil
+			}
+			url := m.listeners[m.selected].URL()
+			if err := openURL(url); err != nil {
+				m.err = "failed to open " + url
+				return m, nil
+			}
+			return m, tea.Quit
+		case "c", "y":
+			if len(m.listeners) == 0 {
+				return m, nil
+			}
+			url := m.listeners[m.selected].URL()
+			if err := m.copyText(url); err != nil {
+				m.err = "failed to copy: " + err.Error()
+				return m, nil
+			}
+			m.err = ""
+			m.status = "copied " + url
+		}
+	}
+	return m, nil
+}
+
+// openURL opens url in the default browser.
+func openURL(url string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	return exec.Command(opener, url).Start()
+}
+
+func (m PortsModel) View() string {
+	var lines []string
+	lines = append(lines, "")
+	lines = append(lines, "  "+popupLabelStyle.Render(m.name))
+	lines = append(lines, "")
+	for i, l := range m.listeners {
+		marker := "  "
+		style := popupSuggestionStyle
+		if i == m.selected {
+			marker = "> "
+			style = popupSuggestionSelectedStyle
+		}
+		lines = append(lines, "  "+marker+style.Render(fmt.Sprintf("%-6d %-16s", l.Port, l.Addr))+" "+popupHintStyle.Render(l.Process))
+	}
+	if len(m.listeners) == 0 {
+		lines = append(lines, "  "+popupHintStyle.Render("not listening on any ports"))
+	}
+	lines = append(lines, "")
+	switch {
+	case m.err != "":
+		lines = append(lines, "  "+popupErrStyle.Render(m.err))
+	case m.status != "":
+		lines = append(lines, "  "+popupHintStyle.Render(m.status))
+	default:
+		lines = append(lines, "")
+	}
+	lines = append(lines, "  "+popupHintStyle.Render("↑/↓ select · enter open · c copy URL · esc close"))
+	return strings.Join(lines, "\n")
+}
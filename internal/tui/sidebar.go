@@ -3,21 +3,27 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/allenan/herd/internal/notify"
 	"github.com/allenan/herd/internal/session"
 )
 
 type itemKind int
 
 const (
-	itemProject itemKind = iota
+	itemProject itemKind = iota // group header (a project in the default view)
 	itemSession
+	itemSubgroup // "services" label inside a project; never selected
 )
 
 type visibleItem struct {
 	kind    itemKind
-	project string
-	session *session.Session // nil for project headers
+	group   string           // key of the group the item is listed under
+	label   string           // header text; set on headers only
+	project string           // session's project; set on headers only in the project view
+	session *session.Session // nil for group headers
+	paired  bool             // terminal listed under its parent Claude session
 }
 
 type SidebarModel struct {
@@ -27,6 +33,10 @@ type SidebarModel struct {
 	cursor    int
 	activeID  string
 	filter    string
+	view      sidebarView
+
+	contextWarn int          // context usage (percent) at which the gauge turns amber
+	mutes       notify.Rules // muted sessions and projects are marked
 }
 
 func NewSidebarModel() SidebarModel {
@@ -38,34 +48,39 @@ func NewSidebarModel() SidebarModel {
 func (m *SidebarModel) SetSessions(sessions []session.Session) {
 	m.sessions = sessions
 
-	// Prune collapsed entries for projects that no longer exist
-	projects := make(map[string]bool)
-	for _, s := range sessions {
-		projects[s.Project] = true
+	// Prune collapsed entries for groups that no longer exist
+	groups := make(map[string]bool)
+	for i := range sessions {
+		for _, g := range m.view.groupKeys(&sessions[i]) {
+			groups[g] = true
+		}
 	}
-	for p := range m.collapsed {
-		if !projects[p] {
-			delete(m.collapsed, p)
+	for g := range m.collapsed {
+		if !groups[g] {
+			delete(m.collapsed, g)
 		}
 	}
 
 	m.rebuildItems()
-
-	if m.cursor >= len(m.items) && len(m.items) > 0 {
-		m.cursor = len(m.items) - 1
-	}
+	m.clampCursor()
 }
 
 func (m *SidebarModel) SetActive(id string) {
 	m.activeID = id
 
-	// Auto-expand collapsed project containing the active session
-	for _, s := range m.sessions {
-		if s.ID == id && m.collapsed[s.Project] {
-			m.collapsed[s.Project] = false
-			m.rebuildItems()
-			break
+	// Auto-expand collapsed group containing the active session
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		if s.ID != id {
+			continue
+		}
+		for _, g := range m.view.groupKeys(s) {
+			if m.collapsed[g] {
+				m.collapsed[g] = false
+				m.rebuildItems()
+			}
 		}
+		break
 	}
 
 	for i, item := range m.items {
@@ -74,18 +89,50 @@ func (m *SidebarModel) SetActive(id string) {
 			return
 		}
 	}
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor on a selectable row after the items
+// changed: within range, and off the services label, preferring the row
+// below it.
+func (m *SidebarModel) clampCursor() {
+	if len(m.items) == 0 {
+		m.cursor = 0
+		return
+	}
+	m.cursor = min(max(m.cursor, 0), len(m.items)-1)
+	if m.items[m.cursor].kind != itemSubgroup {
+		return
+	}
+	if m.cursor < len(m.items)-1 {
+		m.cursor++
+	} else if m.cursor > 0 {
+		m.cursor--
+	}
 }
 
 func (m *SidebarModel) MoveUp() {
+	if len(m.items) == 0 {
+		return
+	}
 	if m.cursor > 0 {
 		m.cursor--
 	}
+	if m.items[m.cursor].kind == itemSubgroup && m.cursor > 0 {
+		m.cursor--
+	}
 }
 
 func (m *SidebarModel) MoveDown() {
+	if len(m.items) == 0 {
+		return
+	}
 	if m.cursor < len(m.items)-1 {
 		m.cursor++
 	}
+	if m.items[m.cursor].kind == itemSubgroup && m.cursor < len(m.items)-1 {
+		m.cursor++
+	}
 }
 
 func (m *SidebarModel) Selected() *session.Session {
@@ -111,92 +158,170 @@ func (m *SidebarModel) ToggleCollapse() {
 		return
 	}
 	item := m.items[m.cursor]
-	project := item.project
-	wasCollapsed := m.collapsed[project]
-	m.collapsed[project] = !wasCollapsed
+	if m.view == viewRecent {
+		return // no groups to collapse
+	}
+	group := item.group
+	wasCollapsed := m.collapsed[group]
+	m.collapsed[group] = !wasCollapsed
 
 	if !wasCollapsed {
-		// Collapsing — move cursor to the project header
+		// Collapsing — move cursor to the group header
 		m.rebuildItems()
 		for i, it := range m.items {
-			if it.kind == itemProject && it.project == project {
+			if it.kind == itemProject && it.group == group {
 				m.cursor = i
 				return
 			}
 		}
 	} else {
 		m.rebuildItems()
+		m.clampCursor()
 	}
 }
 
 func (m *SidebarModel) rebuildItems() {
 	m.items = nil
 
-	// Group sessions by project in encounter order
-	type projectGroup struct {
-		name     string
-		sessions []*session.Session
+	var matched []*session.Session
+	for i := range m.sessions {
+		if s := &m.sessions[i]; matchesFilter(s, m.filter) {
+			matched = append(matched, s)
+		}
 	}
-	var groups []projectGroup
-	seen := make(map[string]int)
 
-	filterLower := strings.ToLower(m.filter)
+	for _, g := range m.view.group(matched) {
+		if m.view == viewRecent {
+			// Flat list: no header, no nesting
+			for _, s := range g.sessions {
+				m.items = append(m.items, visibleItem{
+					kind:    itemSession,
+					project: s.Project,
+					session: s,
+				})
+			}
+			continue
+		}
 
-	for i := range m.sessions {
-		s := &m.sessions[i]
-		// Apply search filter: match against display name or project
-		if filterLower != "" {
-			nameLower := strings.ToLower(s.DisplayName())
-			projLower := strings.ToLower(s.Project)
-			if !strings.Contains(nameLower, filterLower) && !strings.Contains(projLower, filterLower) {
-				continue
+		header := visibleItem{kind: itemProject, group: g.name, label: g.label}
+		if m.view == viewProject {
+			header.project = g.name
+		}
+		m.items = append(m.items, header)
+		if !m.collapsed[g.name] {
+			m.appendGroupSessions(g)
+		}
+	}
+}
+
+// matchesFilter applies the search filter. "tag:x" terms require a tag
+// starting with x; the remaining text must appear in the display name or
+// project.
+func matchesFilter(s *session.Session, filter string) bool {
+	var text []string
+	for _, term := range strings.Fields(filter) {
+		if prefix, ok := strings.CutPrefix(strings.ToLower(term), "tag:"); ok {
+			if !s.HasTagPrefix(prefix) {
+				return false
 			}
+			continue
 		}
-		if idx, ok := seen[s.Project]; ok {
-			groups[idx].sessions = append(groups[idx].sessions, s)
-		} else {
-			seen[s.Project] = len(groups)
-			groups = append(groups, projectGroup{
-				name:     s.Project,
-				sessions: []*session.Session{s},
-			})
+		text = append(text, term)
+	}
+	if len(text) == 0 {
+		return true
+	}
+	filterLower := strings.ToLower(strings.Join(text, " "))
+	nameLower := strings.ToLower(s.DisplayName())
+	projLower := strings.ToLower(s.Project)
+	return strings.Contains(nameLower, filterLower) || strings.Contains(projLower, filterLower)
+}
+
+// FilteredSessions returns the sessions matching the search filter, once
+// each, regardless of collapsed groups. Used as the target of bulk actions.
+func (m *SidebarModel) FilteredSessions() []*session.Session {
+	var out []*session.Session
+	for i := range m.sessions {
+		if s := &m.sessions[i]; matchesFilter(s, m.filter) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// appendGroupSessions lists a group's Claude sessions, each followed by its
+// paired terminals, then the remaining terminals.
+func (m *SidebarModel) appendGroupSessions(g sessionGroup) {
+	// Paired terminals are listed under their (visible) parent
+	paired := make(map[string][]*session.Session)
+	parents := make(map[string]bool)
+	for _, s := range g.sessions {
+		if s.Type != session.TypeTerminal {
+			parents[s.ID] = true
+		}
+	}
+	for _, s := range g.sessions {
+		if s.Type == session.TypeTerminal && parents[s.ParentID] {
+			paired[s.ParentID] = append(paired[s.ParentID], s)
 		}
 	}
 
-	for _, g := range groups {
+	// Claude sessions first, then terminals
+	for _, s := range g.sessions {
+		if s.Type != session.TypeTerminal {
+			m.items = append(m.items, visibleItem{
+				kind:    itemSession,
+				group:   g.name,
+				project: s.Project,
+				session: s,
+			})
+			for _, t := range paired[s.ID] {
+				m.items = append(m.items, visibleItem{
+					kind:    itemSession,
+					group:   g.name,
+					project: t.Project,
+					session: t,
+					paired:  true,
+				})
+			}
+		}
+	}
+	// In the project view, project services get their own sub-group
+	var svcs []*session.Session
+	for _, s := range g.sessions {
+		if s.Type != session.TypeTerminal || parents[s.ParentID] {
+			continue
+		}
+		if s.Service != nil && m.view == viewProject {
+			svcs = append(svcs, s)
+			continue
+		}
 		m.items = append(m.items, visibleItem{
-			kind:    itemProject,
-			project: g.name,
+			kind:    itemSession,
+			group:   g.name,
+			project: s.Project,
+			session: s,
 		})
-		if !m.collapsed[g.name] {
-			// Claude sessions first, then terminals
-			for _, s := range g.sessions {
-				if s.Type != session.TypeTerminal {
-					m.items = append(m.items, visibleItem{
-						kind:    itemSession,
-						project: g.name,
-						session: s,
-					})
-				}
-			}
-			for _, s := range g.sessions {
-				if s.Type == session.TypeTerminal {
-					m.items = append(m.items, visibleItem{
-						kind:    itemSession,
-						project: g.name,
-						session: s,
-					})
-				}
-			}
+	}
+	if len(svcs) > 0 {
+		m.items = append(m.items, visibleItem{kind: itemSubgroup, group: g.name, project: g.name})
+		for _, s := range svcs {
+			m.items = append(m.items, visibleItem{
+				kind:    itemSession,
+				group:   g.name,
+				project: s.Project,
+				session: s,
+			})
 		}
 	}
 }
 
-// sessionCount returns the total number of sessions in a project group.
-func (m *SidebarModel) sessionCount(project string) int {
+// sessionCount returns the total number of sessions in a group, ignoring
+// the search filter.
+func (m *SidebarModel) sessionCount(group string) int {
 	count := 0
-	for _, s := range m.sessions {
-		if s.Project == project {
+	for i := range m.sessions {
+		if m.view.inGroup(&m.sessions[i], group) {
 			count++
 		}
 	}
@@ -212,6 +337,16 @@ func (m *SidebarModel) CurrentProjectInfo() (project, dir string) {
 	item := m.items[m.cursor]
 	projectName := item.project
 
+	if projectName == "" {
+		// Header of a non-project group: use its first session's project
+		for i := range m.sessions {
+			if m.view.inGroup(&m.sessions[i], item.group) {
+				projectName = m.sessions[i].Project
+				break
+			}
+		}
+	}
+
 	// Find the first session in this project to get the directory
 	for _, s := range m.sessions {
 		if s.Project == projectName {
@@ -254,6 +389,50 @@ func (m *SidebarModel) CursorProject() string {
 	return m.items[m.cursor].project
 }
 
+// SessionOrder returns the IDs of the listed sessions, top to bottom.
+// Sessions in collapsed groups are skipped.
+func (m *SidebarModel) SessionOrder() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, item := range m.items {
+		// The tag view can list a session more than once
+		if item.kind == itemSession && item.session != nil && !seen[item.session.ID] {
+			seen[item.session.ID] = true
+			ids = append(ids, item.session.ID)
+		}
+	}
+	return ids
+}
+
+// SetContextWarn sets the context usage percentage highlighted as high.
+func (m *SidebarModel) SetContextWarn(percent int) {
+	m.contextWarn = percent
+}
+
+// SetMutes sets the notification rules used to mark muted sessions and
+// projects.
+func (m *SidebarModel) SetMutes(rules notify.Rules) {
+	m.mutes = rules
+}
+
+// CurrentView returns the active grouping mode.
+func (m *SidebarModel) CurrentView() sidebarView {
+	return m.view
+}
+
+// SetView switches the grouping mode, keeping the cursor on the same
+// session where possible. Collapsed groups are reset since group names
+// differ between views.
+func (m *SidebarModel) SetView(v sidebarView) {
+	sel := m.Selected()
+	m.view = v
+	m.collapsed = make(map[string]bool)
+	m.rebuildItems()
+	if sel == nil || !m.SetCursorToSession(sel.ID) {
+		m.clampCursor()
+	}
+}
+
 // HasSessions returns true if there are any sessions.
 func (m *SidebarModel) HasSessions() bool {
 	return len(m.sessions) > 0
@@ -263,9 +442,7 @@ func (m *SidebarModel) HasSessions() bool {
 func (m *SidebarModel) SetFilter(text string) {
 	m.filter = text
 	m.rebuildItems()
-	if m.cursor >= len(m.items) && len(m.items) > 0 {
-		m.cursor = len(m.items) - 1
-	}
+	m.clampCursor()
 }
 
 // Filter returns the current filter text.
@@ -290,41 +467,62 @@ func (m SidebarModel) View(width, height int, focused bool, spinnerFrame string,
 			if i > 0 {
 				s += "\n"
 			}
-			s += m.renderProject(item.project, isCursor, focused) + "\n"
+			s += m.renderProject(item.group, item.label, isCursor, focused) + "\n"
+		case itemSubgroup:
+			s += m.renderServicesLabel(item.group) + "\n"
 		case itemSession:
 			isActive := item.session != nil && item.session.ID == m.activeID
-			s += m.renderSession(item.session, isCursor, focused, isActive, spinnerFrame, termSpinnerFrame) + "\n"
+			s += m.renderSession(item.session, item.paired, width, isCursor, focused, isActive, spinnerFrame, termSpinnerFrame) + "\n"
 		}
 	}
 	return s
 }
 
-func (m SidebarModel) renderProject(project string, isCursor, focused bool) string {
+func (m SidebarModel) renderProject(group, label string, isCursor, focused bool) string {
 	chevronChar := "▼"
-	if m.collapsed[project] {
+	if m.collapsed[group] {
 		chevronChar = "▶"
 	}
-	count := fmt.Sprintf("(%d)", m.sessionCount(project))
+	count := fmt.Sprintf("(%d)", m.sessionCount(group))
+	if m.view == viewProject && m.mutes.ProjectMuted(group) {
+		count += " " + mutedGlyph
+	}
 
 	if focused {
 		if isCursor {
 			chevron := selectedStyle.Render(chevronChar)
 			countStr := sessionCountStyle.Render(count)
-			name := selectedStyle.Render(project)
+			name := selectedStyle.Render(label)
 			return fmt.Sprintf(" %s %s %s", chevron, name, countStr)
 		}
 		chevron := chevronStyle.Render(chevronChar)
 		countStr := sessionCountStyle.Render(count)
-		name := projectHeaderStyle.Render(project)
+		name := projectHeaderStyle.Render(label)
 		return fmt.Sprintf(" %s %s %s", chevron, name, countStr)
 	}
 
 	chevron := chevronStyle.Render(chevronChar)
 	countStr := sessionCountBlurredStyle.Render(count)
-	name := projectHeaderBlurredStyle.Render(project)
+	name := projectHeaderBlurredStyle.Render(label)
 	return fmt.Sprintf(" %s %s %s", chevron, name, countStr)
 }
 
+// renderServicesLabel renders the label over a project's services, with
+// how many of them are ready.
+func (m SidebarModel) renderServicesLabel(project string) string {
+	total, ready := 0, 0
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		if s.Project == project && s.Service != nil && s.Status != session.StatusExited {
+			total++
+			if s.Service.Ready {
+				ready++
+			}
+		}
+	}
+	return "    " + chevronStyle.Render(fmt.Sprintf("services %d/%d ready", ready, total))
+}
+
 func truncate(s string, max int) string {
 	runes := []rune(s)
 	if len(runes) <= max {
@@ -333,15 +531,74 @@ func truncate(s string, max int) string {
 	return string(runes[:max-1]) + "~"
 }
 
-func (m SidebarModel) renderSession(sess *session.Session, isCursor, focused, isActive bool, spinnerFrame string, termSpinnerFrame string) string {
+func (m SidebarModel) renderSession(sess *session.Session, paired bool, width int, isCursor, focused, isActive bool, spinnerFrame string, termSpinnerFrame string) string {
 	indicator := statusIndicator(sess, spinnerFrame, termSpinnerFrame)
 	name := sess.DisplayName()
-	if sess.Type == session.TypeTerminal {
+	if paired {
+		name = "└ $ " + name
+	} else if sess.Type == session.TypeTerminal {
 		name = "$ " + name
 	} else if sess.IsWorktree {
 		name = "\u2387 " + name // ⎇ prefix
 	}
-	display := truncate(name, 24)
+
+	// Timing notes, the context gauge and tag chips share the line with
+	// the name: the name gives up some room (down to minNameWidth), notes
+	// and gauge are never cut and the chips take what is left.
+	const maxNameWidth, minNameWidth = 24, 10
+	avail := width - 6 // " GG I " prefix
+	if width <= 0 {
+		avail = maxNameWidth
+	}
+	note := timingNote(sess)
+	if note != "" {
+		avail -= len(note) + 1
+	}
+	muted := m.mutes.SessionMuted(sess.ID) || m.mutes.ProjectMuted(sess.Project)
+	if muted {
+		avail -= 3
+	}
+	var conflict string
+	if c := sess.PortConflict; c != nil {
+		conflict = fmt.Sprintf("%s :%d %s", portConflictGlyph, c.Port, truncate(c.Holder, 12))
+		avail -= len([]rune(conflict)) + 1
+	}
+	var gauge string
+	if sess.ContextPercent > 0 {
+		gauge = fmt.Sprintf("%d%%", sess.ContextPercent)
+		avail -= len(gauge) + 1
+	}
+	nameWidth := min(maxNameWidth, avail)
+	chips := tagChips(sess.Tags)
+	if chips != "" {
+		if room := avail - len([]rune(chips)) - 1; room < nameWidth {
+			nameWidth = max(room, minNameWidth)
+		}
+	}
+	display := truncate(name, nameWidth)
+	if chips != "" {
+		if rest := avail - len([]rune(display)) - 1; rest >= 3 {
+			chips = " " + tagChipStyle.Render(truncate(chips, rest))
+		} else {
+			chips = ""
+		}
+	}
+	if gauge != "" {
+		style := contextGaugeStyle
+		if m.contextWarn > 0 && sess.ContextPercent >= m.contextWarn {
+			style = contextGaugeWarnStyle
+		}
+		chips = " " + style.Render(gauge) + chips
+	}
+	if note != "" {
+		chips = " " + timingNoteStyle.Render(note) + chips
+	}
+	if conflict != "" {
+		chips = " " + portConflictStyle.Render(conflict) + chips
+	}
+	if muted {
+		chips = " " + mutedGlyph + chips
+	}
 
 	// All sessions use the same layout: " GG  I name"
 	// where GG = 2-char glyph column (▸ + space, or 2 spaces),
@@ -371,7 +628,45 @@ func (m SidebarModel) renderSession(sess *session.Session, isCursor, focused, is
 		}
 	}
 
-	return fmt.Sprintf(" %s %s %s", glyph, indicator, styledName)
+	return fmt.Sprintf(" %s %s %s%s", glyph, indicator, styledName, chips)
+}
+
+// timingNote says how long a session has been waiting on the user, or how
+// long its finished run took.
+func timingNote(sess *session.Session) string {
+	if !sess.RestartingAt.IsZero() {
+		return "restarting"
+	}
+	switch sess.Status {
+	case session.StatusInput, session.StatusPlanReady:
+		return "waiting " + shortDuration(time.Since(sess.StatusSince()))
+	case session.StatusDone:
+		if d, ok := sess.LastRun(); ok {
+			return "ran " + shortDuration(d)
+		}
+	}
+	return ""
+}
+
+// shortDuration formats d coarsely: 40s, 12m, 3h5m.
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// tagChips renders tags as "#a #b".
+func tagChips(tags []string) string {
+	chips := make([]string, len(tags))
+	for i, t := range tags {
+		chips[i] = "#" + t
+	}
+	return strings.Join(chips, " ")
 }
 
 func statusIndicator(sess *session.Session, spinnerFrame string, termSpinnerFrame string) string {
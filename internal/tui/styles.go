@@ -44,6 +44,10 @@ var (
 			Foreground(colorError).
 			PaddingLeft(1)
 
+	infoStyle = lipgloss.NewStyle().
+			Foreground(colorText).
+			PaddingLeft(1)
+
 	titleBlurredStyle = lipgloss.NewStyle().
 				Faint(true).
 				PaddingLeft(1).
@@ -65,6 +69,10 @@ var (
 			Faint(true).
 			PaddingLeft(1)
 
+	infoBlurredStyle = lipgloss.NewStyle().
+				Faint(true).
+				PaddingLeft(1)
+
 	statusRunningStyle = lipgloss.NewStyle().Foreground(colorClaude)
 	statusInput        = lipgloss.NewStyle().Foreground(colorWarning).Render("!")
 	statusIdle         = lipgloss.NewStyle().Foreground(colorInactive).Render("●")
@@ -91,6 +99,23 @@ var (
 	// Chevron styles
 	chevronStyle = lipgloss.NewStyle().Foreground(colorSubtle)
 
+	// Tag chips after a session name
+	tagChipStyle = lipgloss.NewStyle().Foreground(colorSubtle)
+
+	// "waiting 12m" / "ran 4m" after a session name
+	timingNoteStyle = lipgloss.NewStyle().Foreground(colorInactive)
+
+	// Context window usage after a Claude session name
+	contextGaugeStyle     = lipgloss.NewStyle().Foreground(colorInactive)
+	contextGaugeWarnStyle = lipgloss.NewStyle().Foreground(colorWarning)
+
+	// ":3000 feature-x" after a terminal whose port another session holds
+	portConflictStyle = lipgloss.NewStyle().Foreground(colorWarning)
+	portConflictGlyph = "⚠"
+
+	// Marks sessions and projects with notifications muted
+	mutedGlyph = "🔇"
+
 	// Cursor indicator
 	cursorGlyph = lipgloss.NewStyle().Foreground(colorClaude).Bold(true).Render("▸")
 
@@ -113,3 +138,12 @@ var (
 				Bold(true).
 				PaddingLeft(1)
 )
+
+// SetAccentColor gives the sidebar title and selection a profile's own
+// color, to tell profiles apart. c is a hex color or an ANSI color number.
+func SetAccentColor(c string) {
+	color := lipgloss.Color(c)
+	titleStyle = titleStyle.Foreground(color)
+	selectedStyle = selectedStyle.Foreground(color)
+	cursorGlyph = lipgloss.NewStyle().Foreground(color).Bold(true).Render("▸")
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// sidebarView selects how the sidebar groups and orders sessions. The
+// value is persisted in state, so it must stay stable.
+type sidebarView string
+
+const (
+	viewProject sidebarView = ""       // grouped by project, in user order
+	viewStatus  sidebarView = "status" // grouped by status, most urgent first
+	viewRecent  sidebarView = "recent" // flat list, most recently active first
+	viewBranch  sidebarView = "branch" // grouped by worktree branch
+	viewTags    sidebarView = "tags"   // grouped by tag; a session is listed under each of its tags
+)
+
+// sidebarViews is the order the view key cycles through.
+var sidebarViews = []sidebarView{viewProject, viewStatus, viewRecent, viewBranch, viewTags}
+
+// Catch-all groups, listed last in their views. Their keys start with a
+// byte that can't be typed, so no branch or tag can collide with them;
+// groupLabel strips it.
+const (
+	noBranchGroup = "\x00no worktree"
+	noTagGroup    = "\x00untagged"
+)
+
+// statusGroups orders the status view, most urgent first.
+var statusGroups = []struct {
+	status session.Status
+	label  string
+}{
+	{session.StatusInput, "needs input"},
+	{session.StatusPlanReady, "plan ready"},
+	{session.StatusDone, "done"},
+	{session.StatusRunning, "running"},
+	{session.StatusService, "services"},
+	{session.StatusIdle, "idle"},
+	{session.StatusShell, "shells"},
+	{session.StatusExited, "exited"},
+}
+
+// parseSidebarView returns the view stored in state, falling back to the
+// project view for unknown values.
+func parseSidebarView(s string) sidebarView {
+	for _, v := range sidebarViews {
+		if string(v) == s {
+			return v
+		}
+	}
+	return viewProject
+}
+
+// next returns the view after v in cycling order.
+func (v sidebarView) next() sidebarView {
+	for i, sv := range sidebarViews {
+		if sv == v {
+			return sidebarViews[(i+1)%len(sidebarViews)]
+		}
+	}
+	return viewProject
+}
+
+// label is the short name shown in the sidebar title.
+func (v sidebarView) label() string {
+	switch v {
+	case viewStatus:
+		return "by status"
+	case viewRecent:
+		return "recent"
+	case viewBranch:
+		return "by branch"
+	case viewTags:
+		return "by tag"
+	default:
+		return "by project"
+	}
+}
+
+// groupKeys returns the keys of the groups a session is listed under in
+// view v. Only the tag view can return more than one; tags are lowercased,
+// matching HasTag. The flat recent view has a single unnamed group.
+func (v sidebarView) groupKeys(s *session.Session) []string {
+	switch v {
+	case viewStatus:
+		for _, g := range statusGroups {
+			if g.status == s.Status {
+				return []string{g.label}
+			}
+		}
+		return []string{"running"} // not yet detected
+	case viewRecent:
+		return []string{""}
+	case viewBranch:
+		if s.WorktreeBranch != "" {
+			return []string{s.WorktreeBranch}
+		}
+		return []string{noBranchGroup}
+	case viewTags:
+		if len(s.Tags) == 0 {
+			return []string{noTagGroup}
+		}
+		keys := make([]string, len(s.Tags))
+		for i, t := range s.Tags {
+			keys[i] = strings.ToLower(t)
+		}
+		return keys
+	default:
+		return []string{s.Project}
+	}
+}
+
+// groupLabel is the header shown for a group key, spelled as session s
+// spells it.
+func (v sidebarView) groupLabel(s *session.Session, key string) string {
+	if label, ok := strings.CutPrefix(key, "\x00"); ok {
+		return label
+	}
+	if v == viewTags {
+		for _, t := range s.Tags {
+			if strings.ToLower(t) == key {
+				return t
+			}
+		}
+	}
+	return key
+}
+
+// inGroup reports whether s is listed under group in view v.
+func (v sidebarView) inGroup(s *session.Session, group string) bool {
+	for _, k := range v.groupKeys(s) {
+		if k == group {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionGroup is a header and the sessions listed under it.
+type sessionGroup struct {
+	name     string // key from groupKeys
+	label    string // shown in the header
+	sessions []*session.Session
+}
+
+// group splits sessions into the groups of view v, in display order.
+// Sessions keep their relative (user-defined) order within a group, except
+// in the recent view, which is sorted by activity.
+func (v sidebarView) group(sessions []*session.Session) []sessionGroup {
+	var groups []sessionGroup
+	seen := make(map[string]int)
+	for _, s := range sessions {
+		for _, key := range v.groupKeys(s) {
+			if idx, ok := seen[key]; ok {
+				groups[idx].sessions = append(groups[idx].sessions, s)
+			} else {
+				seen[key] = len(groups)
+				groups = append(groups, sessionGroup{name: key, label: v.groupLabel(s, key), sessions: []*session.Session{s}})
+			}
+		}
+	}
+
+	switch v {
+	case viewStatus:
+		rank := make(map[string]int, len(statusGroups))
+		for i, g := range statusGroups {
+			rank[g.label] = i
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			return rank[groups[i].name] < rank[groups[j].name]
+		})
+	case viewBranch:
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].name != noBranchGroup && groups[j].name == noBranchGroup
+		})
+	case viewTags:
+		sort.SliceStable(groups, func(i, j int) bool {
+			if (groups[i].name == noTagGroup) != (groups[j].name == noTagGroup) {
+				return groups[j].name == noTagGroup
+			}
+			return groups[i].name < groups[j].name
+		})
+	case viewRecent:
+		for _, g := range groups {
+			sort.SliceStable(g.sessions, func(i, j int) bool {
+				return g.sessions[i].ActivityTime().After(g.sessions[j].ActivityTime())
+			})
+		}
+	}
+	return groups
+}
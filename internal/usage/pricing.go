@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// price is the list price of a model in USD per million tokens.
+type price struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// modelPrices maps a model ID fragment to its price. Entries are matched in
+// order, so more specific fragments come first. Only model versions whose
+// price is known are listed: a newer version of a family may cost something
+// else entirely, so it is reported as unpriced rather than guessed. Costs
+// are estimates: they ignore batch/priority discounts and long-context
+// surcharges.
+var modelPrices = []struct {
+	fragment string
+	price    price
+}{
+	{"opus-4-5", price{5, 25, 6.25, 0.50}},
+	{"opus-4-1", price{15, 75, 18.75, 1.50}},
+	{"opus-4-2025", price{15, 75, 18.75, 1.50}}, // claude-opus-4-20250514
+	{"3-opus", price{15, 75, 18.75, 1.50}},
+	{"sonnet-4-5", price{3, 15, 3.75, 0.30}},
+	{"sonnet-4-2025", price{3, 15, 3.75, 0.30}}, // claude-sonnet-4-20250514
+	{"3-7-sonnet", price{3, 15, 3.75, 0.30}},
+	{"3-5-sonnet", price{3, 15, 3.75, 0.30}},
+	{"haiku-4-5", price{1, 5, 1.25, 0.10}},
+	{"3-5-haiku", price{0.80, 4, 1, 0.08}},
+	{"3-haiku", price{0.25, 1.25, 0.30, 0.03}},
+}
+
+// priceFor returns the price of a model, and false if it isn't known.
+func priceFor(model string) (price, bool) {
+	model = strings.ToLower(model)
+	for _, mp := range modelPrices {
+		if strings.Contains(model, mp.fragment) {
+			return mp.price, true
+		}
+	}
+	return price{}, false
+}
+
+// cost returns the estimated USD cost of u at model's prices, and false if
+// the model's price isn't known.
+func cost(model string, u Usage) (float64, bool) {
+	p, ok := priceFor(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(u.InputTokens)*p.input +
+		float64(u.OutputTokens)*p.output +
+		float64(u.CacheCreationTokens)*p.cacheWrite +
+		float64(u.CacheReadTokens)*p.cacheRead) / 1e6, true
+}
+
+// CostLabel renders the estimated cost, e.g. "~$1.20". Usage of models
+// without a known price makes it "unknown", or "~$1.20 + unknown" next to
+// priced usage.
+func (u Usage) CostLabel() string {
+	switch {
+	case !u.Unpriced:
+		return fmt.Sprintf("~$%.2f", u.CostUSD)
+	case u.CostUSD > 0:
+		return fmt.Sprintf("~$%.2f + unknown", u.CostUSD)
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,58 @@
+package usage
+
+import "testing"
+
+func TestCost(t *testing.T) {
+	million := Usage{InputTokens: 1e6, OutputTokens: 1e6, CacheCreationTokens: 1e6, CacheReadTokens: 1e6}
+	tests := []struct {
+		model  string
+		want   float64
+		priced bool
+	}{
+		{"claude-opus-4-5-20251101", 5 + 25 + 6.25 + 0.50, true},
+		{"claude-opus-4-1-20250805", 15 + 75 + 18.75 + 1.50, true},
+		{"claude-opus-4-20250514", 15 + 75 + 18.75 + 1.50, true},
+		{"claude-sonnet-4-5-20250929", 3 + 15 + 3.75 + 0.30, true},
+		{"Claude-Haiku-4-5", 1 + 5 + 1.25 + 0.10, true},
+		{"claude-3-5-haiku-20241022", 0.80 + 4 + 1 + 0.08, true},
+		{"claude-3-haiku-20240307", 0.25 + 1.25 + 0.30 + 0.03, true},
+		// Newer versions aren't guessed from their family
+		{"claude-opus-4-6", 0, false},
+		{"claude-sonnet-5", 0, false},
+		{"<synthetic>", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, priced := cost(tt.model, million)
+		if priced != tt.priced || got-tt.want > 1e-9 || tt.want-got > 1e-9 {
+			t.Errorf("cost(%q) = %v, %v, want %v, %v", tt.model, got, priced, tt.want, tt.priced)
+		}
+	}
+}
+
+func TestCostLabel(t *testing.T) {
+	tests := []struct {
+		u    Usage
+		want string
+	}{
+		{Usage{}, "~$0.00"},
+		{Usage{CostUSD: 1.204}, "~$1.20"},
+		{Usage{CostUSD: 1.2, Unpriced: true}, "~$1.20 + unknown"},
+		{Usage{InputTokens: 10, Unpriced: true}, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.u.CostLabel(); got != tt.want {
+			t.Errorf("%+v.CostLabel() = %q, want %q", tt.u, got, tt.want)
+		}
+	}
+}
+
+func TestAddKeepsUnpriced(t *testing.T) {
+	var total Usage
+	total.Add(Usage{InputTokens: 10, CostUSD: 1})
+	total.Add(Usage{InputTokens: 5, Unpriced: true})
+	total.Add(Usage{InputTokens: 1, CostUSD: 0.5})
+	if want := (Usage{InputTokens: 16, CostUSD: 1.5, Unpriced: true}); total != want {
+		t.Errorf("total = %+v, want %+v", total, want)
+	}
+}
@@ -0,0 +1,77 @@
+package usage
+
+import (
+	"github.com/allenan/herd/internal/session"
+)
+
+// Claim attributes transcripts that appeared since the last call to the
+// herd sessions they belong to, recording their IDs in
+// session.Transcripts. It reports whether any session changed.
+//
+// Transcripts carry only a working directory, so attribution is heuristic:
+// a new transcript goes to the Claude session in that directory that was
+// created before it started and was active most recently (submitting the
+// first prompt flips a session to running). This is exact when a directory
+// has one session, which worktree sessions guarantee, and when Claim is
+// polled while sessions are in use.
+func (t *Tracker) Claim(sessions []session.Session) bool {
+	byDir := make(map[string][]*session.Session)
+	claimed := make(map[string]bool)
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Type != session.TypeClaude {
+			continue
+		}
+		byDir[s.Dir] = append(byDir[s.Dir], s)
+		for _, id := range s.Transcripts {
+			claimed[id] = true
+		}
+	}
+
+	changed := false
+	for dir, group := range byDir {
+		transcripts, err := t.Transcripts(dir)
+		if err != nil {
+			continue
+		}
+		for _, tr := range transcripts {
+			if claimed[tr.ID] {
+				continue
+			}
+			var owner *session.Session
+			for _, s := range group {
+				if s.CreatedAt.After(tr.Start) {
+					continue // transcript predates the session
+				}
+				if owner == nil || s.ActivityTime().After(owner.ActivityTime()) {
+					owner = s
+				}
+			}
+			if owner != nil {
+				owner.Transcripts = append(owner.Transcripts, tr.ID)
+				claimed[tr.ID] = true
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// SessionUsage sums the usage of every transcript attributed to s.
+func (t *Tracker) SessionUsage(s *session.Session) Usage {
+	var total Usage
+	for _, id := range s.Transcripts {
+		if tr, ok := t.Get(s.Dir, id); ok {
+			total.Add(tr.Usage)
+		}
+	}
+	return total
+}
+
+// Latest returns the most recent transcript attributed to s.
+func (t *Tracker) Latest(s *session.Session) (Transcript, bool) {
+	if len(s.Transcripts) == 0 {
+		return Transcript{}, false
+	}
+	return t.Get(s.Dir, s.Transcripts[len(s.Transcripts)-1])
+}
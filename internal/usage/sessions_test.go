@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/allenan/herd/internal/session"
+)
+
+// at is a time of day on the fixtures' day.
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC)
+}
+
+// writeStarted writes a transcript whose first entry is at start.
+func writeStarted(t *testing.T, configDir, cwd, id string, start time.Time) {
+	t.Helper()
+	dir := ProjectDir(configDir, cwd)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := fmt.Sprintf(`{"type":"user","timestamp":%q,"message":{"content":"hi"}}`+"\n", start.Format(time.RFC3339))
+	writeTranscript(t, filepath.Join(dir, id+".jsonl"), line)
+}
+
+func TestClaim(t *testing.T) {
+	configDir := t.TempDir()
+	writeStarted(t, configDir, "/work/app", "early", at(10, 0))
+	writeStarted(t, configDir, "/work/app", "late", at(12, 0))
+	writeStarted(t, configDir, "/work/app", "taken", at(12, 30))
+	writeStarted(t, configDir, "/work/api", "before", at(8, 0))
+	writeStarted(t, configDir, "/work/web", "shell", at(10, 0))
+
+	sessions := []session.Session{
+		// Created before both app transcripts, last active at 11:00
+		{ID: "a", Type: session.TypeClaude, Dir: "/work/app", CreatedAt: at(9, 0), LastActiveAt: at(11, 0)},
+		// Created after "early", active most recently
+		{ID: "b", Type: session.TypeClaude, Dir: "/work/app", CreatedAt: at(11, 0), LastActiveAt: at(11, 30)},
+		// Already owns "taken"
+		{ID: "c", Type: session.TypeClaude, Dir: "/work/app", CreatedAt: at(8, 0), Transcripts: []string{"taken"}},
+		// Created after the only transcript in its directory
+		{ID: "d", Type: session.TypeClaude, Dir: "/work/api", CreatedAt: at(9, 0)},
+		// Terminals never own transcripts
+		{ID: "e", Type: session.TypeTerminal, Dir: "/work/web", CreatedAt: at(9, 0)},
+	}
+	tracker := NewTracker(configDir)
+	if !tracker.Claim(sessions) {
+		t.Error("Claim = false, want true")
+	}
+
+	want := map[string][]string{
+		"a": {"early"},
+		"b": {"late"},
+		"c": {"taken"},
+		"d": nil,
+		"e": nil,
+	}
+	for _, s := range sessions {
+		if !slices.Equal(s.Transcripts, want[s.ID]) {
+			t.Errorf("session %s transcripts = %v, want %v", s.ID, s.Transcripts, want[s.ID])
+		}
+	}
+
+	if tracker.Claim(sessions) {
+		t.Error("second Claim = true, want false")
+	}
+}
+
+func TestSessionUsage(t *testing.T) {
+	configDir := t.TempDir()
+	dir := ProjectDir(configDir, "/work/app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTranscript(t, filepath.Join(dir, "one.jsonl"), assistantLine(sonnet, "m1", "r1", 1000, 0)+"\n")
+	writeTranscript(t, filepath.Join(dir, "two.jsonl"), assistantLine(sonnet, "m1", "r1", 2000, 0)+"\n")
+
+	s := &session.Session{Dir: "/work/app", Transcripts: []string{"one", "two", "gone"}}
+	if got := NewTracker(configDir).SessionUsage(s); got.InputTokens != 3000 {
+		t.Errorf("SessionUsage input tokens = %d, want 3000", got.InputTokens)
+	}
+}
@@ -0,0 +1,247 @@
+// Package usage reads Claude Code's JSONL transcripts to report token usage
+// and estimated cost per herd session.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Usage is a token count with its estimated cost.
+type Usage struct {
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+	Unpriced            bool    `json:"unpriced,omitempty"` // includes models without a known price, not in CostUSD
+}
+
+// Add accumulates o into u.
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheCreationTokens += o.CacheCreationTokens
+	u.CacheReadTokens += o.CacheReadTokens
+	u.CostUSD += o.CostUSD
+	u.Unpriced = u.Unpriced || o.Unpriced
+}
+
+func (u *Usage) sub(o Usage) {
+	u.InputTokens -= o.InputTokens
+	u.OutputTokens -= o.OutputTokens
+	u.CacheCreationTokens -= o.CacheCreationTokens
+	u.CacheReadTokens -= o.CacheReadTokens
+	u.CostUSD -= o.CostUSD
+}
+
+// Total returns all tokens processed, including cache traffic.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+// Transcript is the aggregated usage of one Claude Code conversation.
+type Transcript struct {
+	ID    string    // Claude session ID (file name without .jsonl)
+	Path  string    // absolute path of the JSONL file
+	Start time.Time // timestamp of the first entry
+	Model string    // model of the latest assistant message
+	Usage Usage
+	// Context is the prompt size of the latest main-thread request
+	// (input plus cache reads and writes), i.e. how full the context is.
+	Context int64
+
+	offset   int64            // bytes consumed so far
+	messages map[string]Usage // per-message usage, to de-duplicate streamed entries
+}
+
+// entry is the subset of a transcript line herd cares about.
+type entry struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	RequestID   string    `json:"requestId"`
+	IsSidechain bool      `json:"isSidechain"` // subagent traffic
+	Message     struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// DefaultContextWindow is the context size of current Claude models, in
+// tokens. Profiles using 1M-context models can override it.
+const DefaultContextWindow = 200_000
+
+// ContextPercent returns how full a context window of the given size is,
+// from 0 to 100.
+func (tr Transcript) ContextPercent(window int) int {
+	if window <= 0 {
+		window = DefaultContextWindow
+	}
+	pct := int(tr.Context * 100 / int64(window))
+	return min(pct, 100)
+}
+
+// Tracker caches parsed transcripts and re-reads only what was appended
+// since the last call, so it is cheap to poll. Safe for concurrent use.
+type Tracker struct {
+	configDir string
+
+	mu    sync.Mutex
+	files map[string]*Transcript // by path
+}
+
+// NewTracker reads transcripts from the given Claude config directory
+// (e.g. ~/.claude).
+func NewTracker(configDir string) *Tracker {
+	return &Tracker{
+		configDir: configDir,
+		files:     make(map[string]*Transcript),
+	}
+}
+
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ProjectDir returns the directory Claude Code stores transcripts for
+// conversations started in cwd: every non-alphanumeric character of the
+// path becomes '-'.
+func ProjectDir(configDir, cwd string) string {
+	return filepath.Join(configDir, "projects", unsafePathChars.ReplaceAllString(cwd, "-"))
+}
+
+// Transcripts returns the up-to-date transcripts of conversations started
+// in cwd, oldest first. A directory without transcripts is not an error.
+func (t *Tracker) Transcripts(cwd string) ([]Transcript, error) {
+	paths, err := filepath.Glob(filepath.Join(ProjectDir(t.configDir, cwd), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []Transcript
+	for _, path := range paths {
+		tr, err := t.refresh(path)
+		if err != nil || tr.Start.IsZero() {
+			continue // unreadable, or no timestamped entries yet
+		}
+		out = append(out, *tr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+// Get returns the transcript with the given ID in cwd.
+func (t *Tracker) Get(cwd, id string) (Transcript, bool) {
+	path := filepath.Join(ProjectDir(t.configDir, cwd), id+".jsonl")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, err := t.refresh(path)
+	if err != nil {
+		return Transcript{}, false
+	}
+	return *tr, true
+}
+
+// refresh parses lines appended to path since the last call. The caller
+// must hold t.mu.
+func (t *Tracker) refresh(path string) (*Transcript, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(t.files, path)
+		return nil, err
+	}
+
+	tr := t.files[path]
+	if tr == nil || info.Size() < tr.offset {
+		// New, or rewritten from scratch
+		tr = &Transcript{
+			ID:       strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+			Path:     path,
+			messages: make(map[string]Usage),
+		}
+		t.files[path] = tr
+	}
+	if info.Size() == tr.offset {
+		return tr, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(tr.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// A partial last line is still being written; retry next time
+			break
+		}
+		tr.offset += int64(len(line))
+		tr.apply(line)
+	}
+	return tr, nil
+}
+
+// apply folds one transcript line into the totals. Claude Code writes one
+// entry per content block, all carrying the same message usage, so usage is
+// keyed by message and the latest entry wins.
+func (tr *Transcript) apply(line []byte) {
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return
+	}
+	if tr.Start.IsZero() && !e.Timestamp.IsZero() {
+		tr.Start = e.Timestamp
+	}
+	if e.Type != "assistant" || e.Message.Usage == nil {
+		return
+	}
+	if e.Message.Model != "" && !strings.HasPrefix(e.Message.Model, "<") {
+		tr.Model = e.Message.Model // skip placeholders like "<synthetic>"
+	}
+
+	u := Usage{
+		InputTokens:         e.Message.Usage.InputTokens,
+		OutputTokens:        e.Message.Usage.OutputTokens,
+		CacheCreationTokens: e.Message.Usage.CacheCreationInputTokens,
+		CacheReadTokens:     e.Message.Usage.CacheReadInputTokens,
+	}
+	var priced bool
+	u.CostUSD, priced = cost(e.Message.Model, u)
+	u.Unpriced = !priced && u.Total() > 0 // "<synthetic>" entries carry no tokens
+	if !e.IsSidechain && u.Total() > 0 {
+		// Placeholders carry no tokens and say nothing of the context
+		tr.Context = u.InputTokens + u.CacheReadTokens + u.CacheCreationTokens
+	}
+
+	if e.Message.ID == "" {
+		tr.Usage.Add(u) // nothing to de-duplicate on
+		return
+	}
+	key := e.Message.ID + "/" + e.RequestID
+	if prev, ok := tr.messages[key]; ok {
+		tr.Usage.sub(prev)
+	}
+	tr.messages[key] = u
+	tr.Usage.Add(u)
+}
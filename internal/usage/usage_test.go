@@ -0,0 +1,166 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// assistantLine builds a transcript entry for an assistant message.
+func assistantLine(model, msgID, reqID string, in, out int64) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":"2026-03-01T10:00:00Z","requestId":%q,`+
+		`"message":{"id":%q,"model":%q,"usage":{"input_tokens":%d,"output_tokens":%d,`+
+		`"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}`, reqID, msgID, model, in, out)
+}
+
+const sonnet = "claude-sonnet-4-5-20250929"
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  Usage
+	}{
+		{
+			"entries of one message count once",
+			[]string{
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+			},
+			Usage{InputTokens: 1000, OutputTokens: 50, CostUSD: 0.00375},
+		},
+		{
+			"latest entry of a message wins",
+			[]string{
+				assistantLine(sonnet, "m1", "r1", 1000, 5),
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+			},
+			Usage{InputTokens: 1000, OutputTokens: 50, CostUSD: 0.00375},
+		},
+		{
+			"different messages add up",
+			[]string{
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+				assistantLine(sonnet, "m2", "r2", 2000, 100),
+			},
+			Usage{InputTokens: 3000, OutputTokens: 150, CostUSD: 0.01125},
+		},
+		{
+			"same message in another request",
+			[]string{
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+				assistantLine(sonnet, "m1", "r2", 1000, 50),
+			},
+			Usage{InputTokens: 2000, OutputTokens: 100, CostUSD: 0.0075},
+		},
+		{
+			"no message ID to de-duplicate on",
+			[]string{
+				assistantLine(sonnet, "", "", 1000, 50),
+				assistantLine(sonnet, "", "", 1000, 50),
+			},
+			Usage{InputTokens: 2000, OutputTokens: 100, CostUSD: 0.0075},
+		},
+		{
+			"unknown model is unpriced",
+			[]string{assistantLine("claude-future-9", "m1", "r1", 1000, 50)},
+			Usage{InputTokens: 1000, OutputTokens: 50, Unpriced: true},
+		},
+		{
+			"synthetic messages carry no tokens",
+			[]string{assistantLine("<synthetic>", "m1", "r1", 0, 0)},
+			Usage{},
+		},
+		{
+			"other lines are skipped",
+			[]string{
+				`{"type":"user","message":{"usage":{"input_tokens":99}}}`,
+				`{"type":"assistant","message":{"id":"m3"}}`,
+				`not json`,
+				assistantLine(sonnet, "m1", "r1", 1000, 50),
+			},
+			Usage{InputTokens: 1000, OutputTokens: 50, CostUSD: 0.00375},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &Transcript{messages: make(map[string]Usage)}
+			for _, line := range tt.lines {
+				tr.apply([]byte(line))
+			}
+			if !sameUsage(tr.Usage, tt.want) {
+				t.Errorf("usage = %+v, want %+v", tr.Usage, tt.want)
+			}
+		})
+	}
+}
+
+// sameUsage compares usages, allowing for float rounding in the cost.
+func sameUsage(a, b Usage) bool {
+	d := a.CostUSD - b.CostUSD
+	a.CostUSD, b.CostUSD = 0, 0
+	return a == b && d < 1e-9 && d > -1e-9
+}
+
+func TestApplyModelAndContext(t *testing.T) {
+	tr := &Transcript{messages: make(map[string]Usage)}
+	tr.apply([]byte(`{"type":"user","timestamp":"2026-03-01T09:59:00Z","message":{"content":"hi"}}`))
+	tr.apply([]byte(`{"type":"assistant","requestId":"r1","message":{"id":"m1","model":"` + sonnet + `",` +
+		`"usage":{"input_tokens":10,"cache_read_input_tokens":500,"cache_creation_input_tokens":90}}}`))
+	// Subagent traffic and placeholders don't change the context or model
+	tr.apply([]byte(`{"type":"assistant","isSidechain":true,"requestId":"r2","message":{"id":"m2","model":"claude-haiku-4-5",` +
+		`"usage":{"input_tokens":40000}}}`))
+	tr.apply([]byte(assistantLine("<synthetic>", "m3", "r3", 0, 0)))
+
+	if want := time.Date(2026, 3, 1, 9, 59, 0, 0, time.UTC); !tr.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", tr.Start, want)
+	}
+	if tr.Context != 600 {
+		t.Errorf("Context = %d, want 600", tr.Context)
+	}
+	if tr.Model != "claude-haiku-4-5" {
+		t.Errorf("Model = %q, want %q", tr.Model, "claude-haiku-4-5")
+	}
+}
+
+func TestTrackerReadsAppendedLines(t *testing.T) {
+	configDir := t.TempDir()
+	dir := ProjectDir(configDir, "/work/app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "abc.jsonl")
+	first := assistantLine(sonnet, "m1", "r1", 1000, 50) + "\n"
+	second := assistantLine(sonnet, "m2", "r2", 2000, 100) + "\n"
+
+	// The second line is still being written
+	writeTranscript(t, path, first+second[:20])
+	tracker := NewTracker(configDir)
+	tr, ok := tracker.Get("/work/app", "abc")
+	if !ok || tr.Usage.InputTokens != 1000 {
+		t.Fatalf("Get = %+v, %v, want 1000 input tokens", tr.Usage, ok)
+	}
+
+	writeTranscript(t, path, first+second+first)
+	tr, _ = tracker.Get("/work/app", "abc")
+	if tr.Usage.InputTokens != 3000 {
+		t.Errorf("after append, input tokens = %d, want 3000", tr.Usage.InputTokens)
+	}
+
+	// Rewritten shorter: read again from the start
+	writeTranscript(t, path, second)
+	tr, _ = tracker.Get("/work/app", "abc")
+	if tr.Usage.InputTokens != 2000 {
+		t.Errorf("after rewrite, input tokens = %d, want 2000", tr.Usage.InputTokens)
+	}
+}
+
+func writeTranscript(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}